@@ -0,0 +1,149 @@
+package otsql
+
+import "context"
+
+// CallOptions overrides the behavior of otsql and hooks for calls made with
+// a context, see WithCallOptions.
+// The zero value and nil keep the behavior configured by Options and hooks.
+type CallOptions struct {
+	// hooks enables or disables all hooks, nil to keep them.
+	hooks *bool
+	// hookNames enables or disables hooks by name, see NamedHook.
+	hookNames map[string]bool
+
+	query    *bool
+	args     *bool
+	rowsNext *bool
+}
+
+// CallOption overrides one behavior of calls, see WithCallOptions.
+type CallOption func(*CallOptions)
+
+// NamedHook is implemented by hooks which can be enabled or disabled by name
+// through EnableHooks and DisableHooks.
+type NamedHook interface {
+	Hook
+	Name() string
+}
+
+type callOptionsKey struct{}
+
+// WithCallOptions returns a copy of ctx carrying opts, which apply to every
+// call made with the returned context, including the rows of queries.
+// Options already carried by ctx are kept unless overridden by opts.
+func WithCallOptions(ctx context.Context, opts ...CallOption) context.Context {
+	co := &CallOptions{}
+	if parent := CallOptionsFromContext(ctx); parent != nil {
+		*co = *parent
+		co.hookNames = make(map[string]bool, len(parent.hookNames))
+		for name, b := range parent.hookNames {
+			co.hookNames[name] = b
+		}
+	}
+	for _, opt := range opts {
+		opt(co)
+	}
+	return context.WithValue(ctx, callOptionsKey{}, co)
+}
+
+// CallOptionsFromContext returns options carried by ctx, nil if there is none.
+// All methods of CallOptions are safe to call on nil.
+func CallOptionsFromContext(ctx context.Context) *CallOptions {
+	co, _ := ctx.Value(callOptionsKey{}).(*CallOptions)
+	return co
+}
+
+// DisableHooks disables hooks with names, or all hooks if no name is given.
+// Use it to skip tracing and logging of health check queries, for example.
+func DisableHooks(names ...string) CallOption {
+	return setHooks(false, names)
+}
+
+// EnableHooks enables hooks with names, or all hooks if no name is given,
+// which are disabled by an outer DisableHooks.
+func EnableHooks(names ...string) CallOption {
+	return setHooks(true, names)
+}
+
+func setHooks(b bool, names []string) CallOption {
+	return func(o *CallOptions) {
+		if len(names) == 0 {
+			o.hooks = &b
+			o.hookNames = nil
+			return
+		}
+		if o.hookNames == nil {
+			o.hookNames = make(map[string]bool, len(names))
+		}
+		for _, name := range names {
+			o.hookNames[name] = b
+		}
+	}
+}
+
+// RecordQuery overrides whether hooks record the query, such as
+// the Query option of hook/trace and hook/log.
+func RecordQuery(b bool) CallOption {
+	return func(o *CallOptions) {
+		o.query = &b
+	}
+}
+
+// RecordArgs overrides whether hooks record the args, such as
+// the QueryParams option of hook/trace and the Args option of hook/log.
+func RecordArgs(b bool) CallOption {
+	return func(o *CallOptions) {
+		o.args = &b
+	}
+}
+
+// HookRowsNext overrides Options.RowsNextB and the RowsNext option of hooks.
+func HookRowsNext(b bool) CallOption {
+	return func(o *CallOptions) {
+		o.rowsNext = &b
+	}
+}
+
+// HookEnabled reports whether hook should run.
+func (o *CallOptions) HookEnabled(hook Hook) bool {
+	if o == nil {
+		return true
+	}
+	if named, ok := hook.(NamedHook); ok {
+		if b, ok := o.hookNames[named.Name()]; ok {
+			return b
+		}
+	}
+	return o.hooks == nil || *o.hooks
+}
+
+// Query returns whether to record the query, def is what the hook configured.
+func (o *CallOptions) Query(def bool) bool {
+	if o == nil {
+		return def
+	}
+	return override(o.query, def)
+}
+
+// Args returns whether to record the args, def is what the hook configured.
+func (o *CallOptions) Args(def bool) bool {
+	if o == nil {
+		return def
+	}
+	return override(o.args, def)
+}
+
+// RowsNext returns whether to hook RowsNext calls, def is what is configured.
+func (o *CallOptions) RowsNext(def bool) bool {
+	if o == nil {
+		return def
+	}
+	return override(o.rowsNext, def)
+}
+
+func override(b *bool, def bool) bool {
+	if b == nil {
+		return def
+	}
+	return *b
+}
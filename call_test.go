@@ -0,0 +1,64 @@
+package otsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type namedHook struct {
+	recordHook
+	name string
+}
+
+func (h *namedHook) Name() string { return h.name }
+
+func TestCallOptions(t *testing.T) {
+	trace, log := &namedHook{name: "trace"}, &namedHook{name: "log"}
+	conn := WrapConn(&fakeConn{}, WithHooks(trace, log))
+	exec := func(ctx context.Context) {
+		_, err := conn.(driver.ExecerContext).ExecContext(ctx, "SELECT 1", nil)
+		require.NoError(t, err)
+	}
+
+	ctx := context.Background()
+	exec(WithCallOptions(ctx, DisableHooks()))
+	require.Len(t, trace.events, 0)
+	require.Len(t, log.events, 0)
+
+	ctx = WithCallOptions(ctx, DisableHooks("trace"))
+	exec(ctx)
+	require.Len(t, trace.events, 0)
+	require.Len(t, log.events, 1)
+
+	exec(WithCallOptions(ctx, EnableHooks("trace")))
+	require.Len(t, trace.events, 1)
+	require.Len(t, log.events, 2)
+
+	// the parent context is not modified by WithCallOptions.
+	exec(ctx)
+	require.Len(t, trace.events, 1)
+	require.Len(t, log.events, 3)
+
+	var co *CallOptions
+	require.True(t, co.Query(true))
+	co = CallOptionsFromContext(WithCallOptions(ctx, RecordQuery(false), RecordArgs(true)))
+	require.False(t, co.Query(true))
+	require.True(t, co.Args(false))
+	require.False(t, co.RowsNext(false))
+}
+
+func TestTags(t *testing.T) {
+	hook := &recordHook{}
+	conn := WrapConn(&fakeConn{}, WithHooks(hook))
+
+	ctx := WithTags(context.Background(), "tenant", "j2gg0s", "route", "/users")
+	ctx = WithTags(ctx, "route", "/orders", "dangling")
+	_, err := conn.(driver.ExecerContext).ExecContext(ctx, "SELECT 1", nil)
+	require.NoError(t, err)
+
+	require.Len(t, hook.events, 1)
+	require.Equal(t, map[string]string{"tenant": "j2gg0s", "route": "/orders"}, hook.events[0].Tags)
+}
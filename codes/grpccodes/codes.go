@@ -0,0 +1,18 @@
+// Package grpccodes converts between otsql.Code and gRPC codes.
+// It lives in its own module, so otsql itself does not depend on gRPC.
+package grpccodes
+
+import (
+	"github.com/j2gg0s/otsql"
+	"google.golang.org/grpc/codes"
+)
+
+// ToGRPC converts otsql.Code to gRPC code.
+func ToGRPC(c otsql.Code) codes.Code {
+	return codes.Code(c)
+}
+
+// FromGRPC converts gRPC code to otsql.Code.
+func FromGRPC(c codes.Code) otsql.Code {
+	return otsql.Code(c)
+}
@@ -0,0 +1,13 @@
+module github.com/j2gg0s/otsql/codes/grpccodes
+
+go 1.15
+
+require (
+	// the first commit with otsql.Code, replace it by the first tag containing it.
+	github.com/j2gg0s/otsql v0.0.0-20261017200837-97d7f241db65
+	google.golang.org/grpc v1.31.0
+)
+
+// The replace is only for developing both modules together, it applies when
+// building this module itself and is ignored by modules depending on it.
+replace github.com/j2gg0s/otsql => ../../
@@ -0,0 +1,16 @@
+// Package otelcodes converts otsql.Code to OpenTelemetry span status.
+package otelcodes
+
+import (
+	"github.com/j2gg0s/otsql"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Status returns span status and its description for c.
+// Every code except otsql.CodeOK is treated as codes.Error.
+func Status(c otsql.Code) (codes.Code, string) {
+	if c == otsql.CodeOK {
+		return codes.Ok, ""
+	}
+	return codes.Error, c.String()
+}
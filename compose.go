@@ -0,0 +1,935 @@
+// Code generated by compose_gen.go. DO NOT EDIT.
+
+package otsql
+
+import "database/sql/driver"
+
+// composeConn returns c which exposes only the optional interfaces in flags.
+// nolint
+func composeConn(v connAll, flags connFlag) driver.Conn {
+	switch flags {
+	case 0:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+		}{v, v, v, v, v, v, v}
+	case connPinger:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Pinger
+		}{v, v, v, v, v, v, v, v}
+	case connExecer:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Execer
+		}{v, v, v, v, v, v, v, v}
+	case connPinger | connExecer:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Pinger
+			driver.Execer
+		}{v, v, v, v, v, v, v, v, v}
+	case connExecerContext:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.ExecerContext
+		}{v, v, v, v, v, v, v, v}
+	case connPinger | connExecerContext:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Pinger
+			driver.ExecerContext
+		}{v, v, v, v, v, v, v, v, v}
+	case connExecer | connExecerContext:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Execer
+			driver.ExecerContext
+		}{v, v, v, v, v, v, v, v, v}
+	case connPinger | connExecer | connExecerContext:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Pinger
+			driver.Execer
+			driver.ExecerContext
+		}{v, v, v, v, v, v, v, v, v, v}
+	case connQueryer:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Queryer
+		}{v, v, v, v, v, v, v, v}
+	case connPinger | connQueryer:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Pinger
+			driver.Queryer
+		}{v, v, v, v, v, v, v, v, v}
+	case connExecer | connQueryer:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Execer
+			driver.Queryer
+		}{v, v, v, v, v, v, v, v, v}
+	case connPinger | connExecer | connQueryer:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Pinger
+			driver.Execer
+			driver.Queryer
+		}{v, v, v, v, v, v, v, v, v, v}
+	case connExecerContext | connQueryer:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.ExecerContext
+			driver.Queryer
+		}{v, v, v, v, v, v, v, v, v}
+	case connPinger | connExecerContext | connQueryer:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Pinger
+			driver.ExecerContext
+			driver.Queryer
+		}{v, v, v, v, v, v, v, v, v, v}
+	case connExecer | connExecerContext | connQueryer:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Execer
+			driver.ExecerContext
+			driver.Queryer
+		}{v, v, v, v, v, v, v, v, v, v}
+	case connPinger | connExecer | connExecerContext | connQueryer:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Pinger
+			driver.Execer
+			driver.ExecerContext
+			driver.Queryer
+		}{v, v, v, v, v, v, v, v, v, v, v}
+	case connQueryerContext:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.QueryerContext
+		}{v, v, v, v, v, v, v, v}
+	case connPinger | connQueryerContext:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Pinger
+			driver.QueryerContext
+		}{v, v, v, v, v, v, v, v, v}
+	case connExecer | connQueryerContext:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Execer
+			driver.QueryerContext
+		}{v, v, v, v, v, v, v, v, v}
+	case connPinger | connExecer | connQueryerContext:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Pinger
+			driver.Execer
+			driver.QueryerContext
+		}{v, v, v, v, v, v, v, v, v, v}
+	case connExecerContext | connQueryerContext:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.ExecerContext
+			driver.QueryerContext
+		}{v, v, v, v, v, v, v, v, v}
+	case connPinger | connExecerContext | connQueryerContext:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Pinger
+			driver.ExecerContext
+			driver.QueryerContext
+		}{v, v, v, v, v, v, v, v, v, v}
+	case connExecer | connExecerContext | connQueryerContext:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Execer
+			driver.ExecerContext
+			driver.QueryerContext
+		}{v, v, v, v, v, v, v, v, v, v}
+	case connPinger | connExecer | connExecerContext | connQueryerContext:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Pinger
+			driver.Execer
+			driver.ExecerContext
+			driver.QueryerContext
+		}{v, v, v, v, v, v, v, v, v, v, v}
+	case connQueryer | connQueryerContext:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Queryer
+			driver.QueryerContext
+		}{v, v, v, v, v, v, v, v, v}
+	case connPinger | connQueryer | connQueryerContext:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Pinger
+			driver.Queryer
+			driver.QueryerContext
+		}{v, v, v, v, v, v, v, v, v, v}
+	case connExecer | connQueryer | connQueryerContext:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Execer
+			driver.Queryer
+			driver.QueryerContext
+		}{v, v, v, v, v, v, v, v, v, v}
+	case connPinger | connExecer | connQueryer | connQueryerContext:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Pinger
+			driver.Execer
+			driver.Queryer
+			driver.QueryerContext
+		}{v, v, v, v, v, v, v, v, v, v, v}
+	case connExecerContext | connQueryer | connQueryerContext:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.ExecerContext
+			driver.Queryer
+			driver.QueryerContext
+		}{v, v, v, v, v, v, v, v, v, v}
+	case connPinger | connExecerContext | connQueryer | connQueryerContext:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Pinger
+			driver.ExecerContext
+			driver.Queryer
+			driver.QueryerContext
+		}{v, v, v, v, v, v, v, v, v, v, v}
+	case connExecer | connExecerContext | connQueryer | connQueryerContext:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Execer
+			driver.ExecerContext
+			driver.Queryer
+			driver.QueryerContext
+		}{v, v, v, v, v, v, v, v, v, v, v}
+	case connPinger | connExecer | connExecerContext | connQueryer | connQueryerContext:
+		return struct {
+			driver.Conn
+			driver.ConnPrepareContext
+			driver.ConnBeginTx
+			driver.SessionResetter
+			driver.NamedValueChecker
+			driver.Validator
+			connUnwrapper
+			driver.Pinger
+			driver.Execer
+			driver.ExecerContext
+			driver.Queryer
+			driver.QueryerContext
+		}{v, v, v, v, v, v, v, v, v, v, v, v}
+	}
+
+	panic("unreachable")
+}
+
+// composeRows returns r which exposes only the optional interfaces in flags.
+// nolint
+func composeRows(v rowsAll, flags rowsFlag) driver.Rows {
+	switch flags {
+	case 0:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+		}{v, v}
+	case rowsNextResultSet:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+		}{v, v, v}
+	case rowsColumnTypeScanType:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeScanType
+		}{v, v, v}
+	case rowsNextResultSet | rowsColumnTypeScanType:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeScanType
+		}{v, v, v, v}
+	case rowsColumnTypeDatabaseTypeName:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeDatabaseTypeName
+		}{v, v, v}
+	case rowsNextResultSet | rowsColumnTypeDatabaseTypeName:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeDatabaseTypeName
+		}{v, v, v, v}
+	case rowsColumnTypeScanType | rowsColumnTypeDatabaseTypeName:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeScanType
+			withRowsColumnTypeDatabaseTypeName
+		}{v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeScanType | rowsColumnTypeDatabaseTypeName:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeScanType
+			withRowsColumnTypeDatabaseTypeName
+		}{v, v, v, v, v}
+	case rowsColumnTypeLength:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeLength
+		}{v, v, v}
+	case rowsNextResultSet | rowsColumnTypeLength:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeLength
+		}{v, v, v, v}
+	case rowsColumnTypeScanType | rowsColumnTypeLength:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeScanType
+			withRowsColumnTypeLength
+		}{v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeScanType | rowsColumnTypeLength:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeScanType
+			withRowsColumnTypeLength
+		}{v, v, v, v, v}
+	case rowsColumnTypeDatabaseTypeName | rowsColumnTypeLength:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeLength
+		}{v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeDatabaseTypeName | rowsColumnTypeLength:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeLength
+		}{v, v, v, v, v}
+	case rowsColumnTypeScanType | rowsColumnTypeDatabaseTypeName | rowsColumnTypeLength:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeScanType
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeLength
+		}{v, v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeScanType | rowsColumnTypeDatabaseTypeName | rowsColumnTypeLength:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeScanType
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeLength
+		}{v, v, v, v, v, v}
+	case rowsColumnTypeNullable:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeNullable
+		}{v, v, v}
+	case rowsNextResultSet | rowsColumnTypeNullable:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeNullable
+		}{v, v, v, v}
+	case rowsColumnTypeScanType | rowsColumnTypeNullable:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeScanType
+			withRowsColumnTypeNullable
+		}{v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeScanType | rowsColumnTypeNullable:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeScanType
+			withRowsColumnTypeNullable
+		}{v, v, v, v, v}
+	case rowsColumnTypeDatabaseTypeName | rowsColumnTypeNullable:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeNullable
+		}{v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeDatabaseTypeName | rowsColumnTypeNullable:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeNullable
+		}{v, v, v, v, v}
+	case rowsColumnTypeScanType | rowsColumnTypeDatabaseTypeName | rowsColumnTypeNullable:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeScanType
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeNullable
+		}{v, v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeScanType | rowsColumnTypeDatabaseTypeName | rowsColumnTypeNullable:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeScanType
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeNullable
+		}{v, v, v, v, v, v}
+	case rowsColumnTypeLength | rowsColumnTypeNullable:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeLength
+			withRowsColumnTypeNullable
+		}{v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeLength | rowsColumnTypeNullable:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeLength
+			withRowsColumnTypeNullable
+		}{v, v, v, v, v}
+	case rowsColumnTypeScanType | rowsColumnTypeLength | rowsColumnTypeNullable:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeScanType
+			withRowsColumnTypeLength
+			withRowsColumnTypeNullable
+		}{v, v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeScanType | rowsColumnTypeLength | rowsColumnTypeNullable:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeScanType
+			withRowsColumnTypeLength
+			withRowsColumnTypeNullable
+		}{v, v, v, v, v, v}
+	case rowsColumnTypeDatabaseTypeName | rowsColumnTypeLength | rowsColumnTypeNullable:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeLength
+			withRowsColumnTypeNullable
+		}{v, v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeDatabaseTypeName | rowsColumnTypeLength | rowsColumnTypeNullable:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeLength
+			withRowsColumnTypeNullable
+		}{v, v, v, v, v, v}
+	case rowsColumnTypeScanType | rowsColumnTypeDatabaseTypeName | rowsColumnTypeLength | rowsColumnTypeNullable:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeScanType
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeLength
+			withRowsColumnTypeNullable
+		}{v, v, v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeScanType | rowsColumnTypeDatabaseTypeName | rowsColumnTypeLength | rowsColumnTypeNullable:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeScanType
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeLength
+			withRowsColumnTypeNullable
+		}{v, v, v, v, v, v, v}
+	case rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypePrecisionScale
+		}{v, v, v}
+	case rowsNextResultSet | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v}
+	case rowsColumnTypeScanType | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeScanType
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeScanType | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeScanType
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v}
+	case rowsColumnTypeDatabaseTypeName | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeDatabaseTypeName | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v}
+	case rowsColumnTypeScanType | rowsColumnTypeDatabaseTypeName | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeScanType
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeScanType | rowsColumnTypeDatabaseTypeName | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeScanType
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v, v}
+	case rowsColumnTypeLength | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeLength
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeLength | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeLength
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v}
+	case rowsColumnTypeScanType | rowsColumnTypeLength | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeScanType
+			withRowsColumnTypeLength
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeScanType | rowsColumnTypeLength | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeScanType
+			withRowsColumnTypeLength
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v, v}
+	case rowsColumnTypeDatabaseTypeName | rowsColumnTypeLength | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeLength
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeDatabaseTypeName | rowsColumnTypeLength | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeLength
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v, v}
+	case rowsColumnTypeScanType | rowsColumnTypeDatabaseTypeName | rowsColumnTypeLength | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeScanType
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeLength
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeScanType | rowsColumnTypeDatabaseTypeName | rowsColumnTypeLength | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeScanType
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeLength
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v, v, v}
+	case rowsColumnTypeNullable | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeNullable
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeNullable | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeNullable
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v}
+	case rowsColumnTypeScanType | rowsColumnTypeNullable | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeScanType
+			withRowsColumnTypeNullable
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeScanType | rowsColumnTypeNullable | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeScanType
+			withRowsColumnTypeNullable
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v, v}
+	case rowsColumnTypeDatabaseTypeName | rowsColumnTypeNullable | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeNullable
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeDatabaseTypeName | rowsColumnTypeNullable | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeNullable
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v, v}
+	case rowsColumnTypeScanType | rowsColumnTypeDatabaseTypeName | rowsColumnTypeNullable | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeScanType
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeNullable
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeScanType | rowsColumnTypeDatabaseTypeName | rowsColumnTypeNullable | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeScanType
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeNullable
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v, v, v}
+	case rowsColumnTypeLength | rowsColumnTypeNullable | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeLength
+			withRowsColumnTypeNullable
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeLength | rowsColumnTypeNullable | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeLength
+			withRowsColumnTypeNullable
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v, v}
+	case rowsColumnTypeScanType | rowsColumnTypeLength | rowsColumnTypeNullable | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeScanType
+			withRowsColumnTypeLength
+			withRowsColumnTypeNullable
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeScanType | rowsColumnTypeLength | rowsColumnTypeNullable | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeScanType
+			withRowsColumnTypeLength
+			withRowsColumnTypeNullable
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v, v, v}
+	case rowsColumnTypeDatabaseTypeName | rowsColumnTypeLength | rowsColumnTypeNullable | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeLength
+			withRowsColumnTypeNullable
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeDatabaseTypeName | rowsColumnTypeLength | rowsColumnTypeNullable | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeLength
+			withRowsColumnTypeNullable
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v, v, v}
+	case rowsColumnTypeScanType | rowsColumnTypeDatabaseTypeName | rowsColumnTypeLength | rowsColumnTypeNullable | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsColumnTypeScanType
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeLength
+			withRowsColumnTypeNullable
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v, v, v}
+	case rowsNextResultSet | rowsColumnTypeScanType | rowsColumnTypeDatabaseTypeName | rowsColumnTypeLength | rowsColumnTypeNullable | rowsColumnTypePrecisionScale:
+		return struct {
+			driver.Rows
+			rowsUnwrapper
+			withRowsNextResultSet
+			withRowsColumnTypeScanType
+			withRowsColumnTypeDatabaseTypeName
+			withRowsColumnTypeLength
+			withRowsColumnTypeNullable
+			withRowsColumnTypePrecisionScale
+		}{v, v, v, v, v, v, v, v}
+	}
+
+	panic("unreachable")
+}
@@ -2,286 +2,686 @@ package otsql
 
 import (
 	"context"
+	"database/sql"
 	"database/sql/driver"
+	"errors"
+	"io"
 	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+//go:generate go run compose_gen.go
+
+var connSeq, txSeq, stmtSeq uint64
+
+// connMeta identifies a connection, it is shared by the connection and
+// everything created from it.
+type connMeta struct {
+	// id is unique in process and increases monotonically.
+	id string
+
+	// sessionID is the server-side id of connection, see Options.SessionID.
+	sessionID string
+
+	mu sync.Mutex
+	// tx is the transaction in progress, from begin until commit or rollback.
+	tx *txMeta
+}
+
+type txMeta struct {
+	id   string
+	opts driver.TxOptions
+}
+
+func newConnMeta() *connMeta {
+	return &connMeta{id: strconv.FormatUint(atomic.AddUint64(&connSeq, 1), 10)}
+}
+
+func (m *connMeta) beginTx(opts driver.TxOptions) {
+	tx := &txMeta{
+		id:   strconv.FormatUint(atomic.AddUint64(&txSeq, 1), 10),
+		opts: opts,
+	}
+	m.mu.Lock()
+	m.tx = tx
+	m.mu.Unlock()
+}
+
+func (m *connMeta) endTx() {
+	m.mu.Lock()
+	m.tx = nil
+	m.mu.Unlock()
+}
+
+func (m *connMeta) currentTx() *txMeta {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.tx
+}
+
 // driver.Conn
 type otConn struct {
 	driver.Conn
 	*Options
 
-	connID string
+	meta *connMeta
 }
 
 func (c otConn) Exec(query string, args []driver.Value) (res driver.Result, err error) {
-	evt := newEvent(c.Options, c.connID, MethodExec, query, args)
+	evt := newEvent(c.Options, c.meta, MethodExec, query, args)
 
-	ctx := before(c.Hooks, context.Background(), evt)
+	ctx := before(c.Options, context.Background(), evt)
 	defer func() {
+		if skipped(err) {
+			return
+		}
 		evt.Err = err
-		after(c.Hooks, ctx, evt)
+		after(c.Options, ctx, evt)
 	}()
 
-	execer, ok := c.Conn.(driver.Execer) // nolint
-	if !ok {
-		return nil, driver.ErrSkip
-	}
-	if res, err = execer.Exec(query, args); err != nil {
+	// we already tested driver when wrap conn
+	execer := c.Conn.(driver.Execer) // nolint
+	if err = intercept(c.Options, ctx, evt, func(context.Context) error {
+		res, err := execer.Exec(evt.Query, values(evt, args))
+		evt.Result = res
+		return err
+	}); err != nil {
 		return nil, err
 	}
 
-	return wrapResult(ctx, c.connID, res, c.Options), nil
+	res = evt.Result.(driver.Result)
+	captureResult(evt, res, c.Options)
+	return wrapResult(ctx, c.meta, res, c.Options), nil
 }
 
 func (c otConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (res driver.Result, err error) {
-	evt := newEvent(c.Options, c.connID, MethodExec, query, args)
+	evt := newEvent(c.Options, c.meta, MethodExec, query, args)
 
-	ctx = before(c.Hooks, ctx, evt)
+	ctx = before(c.Options, ctx, evt)
 	defer func() {
+		if skipped(err) {
+			return
+		}
 		evt.Err = err
-		after(c.Hooks, ctx, evt)
+		after(c.Options, ctx, evt)
 	}()
 
-	execer, ok := c.Conn.(driver.ExecerContext)
-	if !ok {
-		return nil, driver.ErrSkip
-	}
-	if res, err = execer.ExecContext(ctx, query, args); err != nil {
+	// we already tested driver when wrap conn
+	execer := c.Conn.(driver.ExecerContext)
+	if err = intercept(c.Options, ctx, evt, func(ctx context.Context) error {
+		res, err := execer.ExecContext(ctx, evt.Query, namedValues(evt, args))
+		evt.Result = res
+		return err
+	}); err != nil {
 		return nil, err
 	}
-	return wrapResult(ctx, c.connID, res, c.Options), nil
+	res = evt.Result.(driver.Result)
+	captureResult(evt, res, c.Options)
+	return wrapResult(ctx, c.meta, res, c.Options), nil
 }
 
 func (c otConn) Query(query string, args []driver.Value) (rows driver.Rows, err error) {
-	evt := newEvent(c.Options, c.connID, MethodQuery, query, args)
+	evt := newEvent(c.Options, c.meta, MethodQuery, query, args)
 
-	ctx := before(c.Hooks, context.Background(), evt)
+	ctx := before(c.Options, context.Background(), evt)
 	defer func() {
+		if skipped(err) {
+			return
+		}
+		// finished by rows, see Options.DeferQueryEndB.
+		if err == nil && c.DeferQueryEndB {
+			return
+		}
 		evt.Err = err
-		after(c.Hooks, ctx, evt)
+		after(c.Options, ctx, evt)
 	}()
 
-	queryer, ok := c.Conn.(driver.Queryer) // nolint
-	if !ok {
-		return nil, driver.ErrSkip
-	}
-	if rows, err = queryer.Query(query, args); err != nil {
+	// we already tested driver when wrap conn
+	queryer := c.Conn.(driver.Queryer) // nolint
+	if err = intercept(c.Options, ctx, evt, func(context.Context) error {
+		rows, err := queryer.Query(evt.Query, values(evt, args))
+		evt.Result = rows
+		return err
+	}); err != nil {
 		return nil, err
 	}
-	return wrapRows(ctx, c.connID, rows, c.Options), nil
+	rows = evt.Result.(driver.Rows)
+	return wrapRows(ctx, c.meta, rows, evt, c.Options), nil
 }
 
 func (c otConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (rows driver.Rows, err error) {
-	evt := newEvent(c.Options, c.connID, MethodQuery, query, args)
+	evt := newEvent(c.Options, c.meta, MethodQuery, query, args)
 
-	ctx = before(c.Hooks, ctx, evt)
+	ctx = before(c.Options, ctx, evt)
 	defer func() {
+		if skipped(err) {
+			return
+		}
+		// finished by rows, see Options.DeferQueryEndB.
+		if err == nil && c.DeferQueryEndB {
+			return
+		}
 		evt.Err = err
-		after(c.Hooks, ctx, evt)
+		after(c.Options, ctx, evt)
 	}()
 
-	queryer, ok := c.Conn.(driver.QueryerContext)
-	if !ok {
-		return nil, driver.ErrSkip
-	}
-
-	if rows, err = queryer.QueryContext(ctx, query, args); err != nil {
+	// we already tested driver when wrap conn
+	queryer := c.Conn.(driver.QueryerContext)
+	if err = intercept(c.Options, ctx, evt, func(ctx context.Context) error {
+		rows, err := queryer.QueryContext(ctx, evt.Query, namedValues(evt, args))
+		evt.Result = rows
+		return err
+	}); err != nil {
 		return nil, err
 	}
-	return wrapRows(ctx, c.connID, rows, c.Options), nil
+	rows = evt.Result.(driver.Rows)
+	return wrapRows(ctx, c.meta, rows, evt, c.Options), nil
 }
 
 func (c otConn) Ping(ctx context.Context) (err error) {
-	evt := newEvent(c.Options, c.connID, MethodPing, "", nil)
+	evt := newEvent(c.Options, c.meta, MethodPing, "", nil)
 
-	ctx = before(c.Hooks, ctx, evt)
+	ctx = before(c.Options, ctx, evt)
 	defer func() {
 		evt.Err = err
-		after(c.Hooks, ctx, evt)
+		after(c.Options, ctx, evt)
 	}()
 
-	pinger, ok := c.Conn.(driver.Pinger)
-	if !ok {
-		return driver.ErrSkip
-	}
-
-	return pinger.Ping(ctx)
+	// we already tested driver when wrap conn
+	return intercept(c.Options, ctx, evt, c.Conn.(driver.Pinger).Ping)
 }
 
 func (c otConn) PrepareContext(ctx context.Context, query string) (stmt driver.Stmt, err error) {
-	evt := newEvent(c.Options, c.connID, MethodPrepare, query, nil)
+	evt := newEvent(c.Options, c.meta, MethodPrepare, query, nil)
+	evt.StmtID = strconv.FormatUint(atomic.AddUint64(&stmtSeq, 1), 10)
 
-	ctx = before(c.Hooks, ctx, evt)
+	ctx = before(c.Options, ctx, evt)
 	defer func() {
 		evt.Err = err
-		after(c.Hooks, ctx, evt)
+		after(c.Options, ctx, evt)
 	}()
 
-	if prepare, ok := c.Conn.(driver.ConnPrepareContext); ok {
-		if stmt, err = prepare.PrepareContext(ctx, query); err != nil {
-			return nil, err
-		}
-	} else {
-		if stmt, err = c.Conn.Prepare(query); err != nil {
-			return nil, err
+	if err = intercept(c.Options, ctx, evt, func(ctx context.Context) error {
+		var stmt driver.Stmt
+		var err error
+		if prepare, ok := c.Conn.(driver.ConnPrepareContext); ok {
+			stmt, err = prepare.PrepareContext(ctx, evt.Query)
+		} else {
+			stmt, err = c.Conn.Prepare(evt.Query)
 		}
+		evt.Result = stmt
+		return err
+	}); err != nil {
+		return nil, err
 	}
 
-	return wrapStmt(c.connID, stmt, query, c.Options), nil
+	stmt = evt.Result.(driver.Stmt)
+	return wrapStmt(c.meta, stmt, evt, c.Options), nil
 }
 
 func (c otConn) Prepare(query string) (stmt driver.Stmt, err error) {
-	evt := newEvent(c.Options, c.connID, MethodPrepare, query, nil)
-	ctx := before(c.Hooks, context.Background(), evt)
+	evt := newEvent(c.Options, c.meta, MethodPrepare, query, nil)
+	evt.StmtID = strconv.FormatUint(atomic.AddUint64(&stmtSeq, 1), 10)
+	ctx := before(c.Options, context.Background(), evt)
 	defer func() {
 		evt.Err = err
-		after(c.Hooks, ctx, evt)
+		after(c.Options, ctx, evt)
 	}()
 
-	stmt, err = c.Conn.Prepare(query)
-	if err != nil {
+	if err = intercept(c.Options, ctx, evt, func(context.Context) error {
+		stmt, err := c.Conn.Prepare(evt.Query)
+		evt.Result = stmt
+		return err
+	}); err != nil {
 		return nil, err
 	}
 
-	return wrapStmt(c.connID, stmt, query, c.Options), nil
+	stmt = evt.Result.(driver.Stmt)
+	return wrapStmt(c.meta, stmt, evt, c.Options), nil
 }
 
 func (c otConn) Begin() (tx driver.Tx, err error) {
-	evt := newEvent(c.Options, c.connID, MethodBegin, "", nil)
-	ctx := before(c.Hooks, context.Background(), evt)
+	c.meta.beginTx(driver.TxOptions{})
+	evt := newEvent(c.Options, c.meta, MethodBegin, "", nil)
+	ctx := before(c.Options, context.Background(), evt)
 	defer func() {
 		evt.Err = err
-		after(c.Hooks, ctx, evt)
+		if err != nil {
+			c.meta.endTx()
+		}
+		after(c.Options, ctx, evt)
 	}()
 
-	tx, err = c.Conn.Begin() // nolint
-	if err != nil {
+	if err = intercept(c.Options, ctx, evt, func(context.Context) error {
+		tx, err := c.Conn.Begin() // nolint
+		evt.Result = tx
+		return err
+	}); err != nil {
 		return nil, err
 	}
-	return wrapTx(ctx, c.connID, tx, c.Options), nil
+	tx = evt.Result.(driver.Tx)
+	return wrapTx(context.Background(), c.meta, tx, c.Options), nil
 }
 
 func (c otConn) BeginTx(ctx context.Context, opts driver.TxOptions) (tx driver.Tx, err error) {
-	evt := newEvent(c.Options, c.connID, MethodBegin, "", nil)
-	ctx = before(c.Hooks, ctx, evt)
+	// commit and rollback don't take context, they use the one begins the transaction.
+	txCtx := ctx
+
+	c.meta.beginTx(opts)
+	evt := newEvent(c.Options, c.meta, MethodBegin, "", nil)
+	ctx = before(c.Options, ctx, evt)
 	defer func() {
 		evt.Err = err
-		after(c.Hooks, ctx, evt)
+		if err != nil {
+			c.meta.endTx()
+		}
+		after(c.Options, ctx, evt)
 	}()
 
-	if beginTx, ok := c.Conn.(driver.ConnBeginTx); ok {
-		if tx, err = beginTx.BeginTx(ctx, opts); err != nil {
-			return nil, err
-		}
-	} else {
-		if tx, err = c.Conn.Begin(); err != nil { // nolint
-			return nil, err
-		}
+	if err = intercept(c.Options, ctx, evt, func(ctx context.Context) error {
+		tx, err := c.beginTx(ctx, opts)
+		evt.Result = tx
+		return err
+	}); err != nil {
+		return nil, err
 	}
-	return wrapTx(ctx, c.connID, tx, c.Options), nil
+	tx = evt.Result.(driver.Tx)
+	return wrapTx(txCtx, c.meta, tx, c.Options), nil
+}
+
+// beginTx falls back to Begin if conn doesn't implement driver.ConnBeginTx,
+// the same as database/sql does.
+func (c otConn) beginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+		return nil, errors.New("sql: driver does not support non-default isolation level")
+	}
+	if opts.ReadOnly {
+		return nil, errors.New("sql: driver does not support read-only transactions")
+	}
+
+	tx, err := c.Conn.Begin() // nolint
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case <-ctx.Done():
+		_ = tx.Rollback()
+		return nil, ctx.Err()
+	default:
+	}
+	return tx, nil
 }
 
 func (c otConn) Close() (err error) {
-	evt := newEvent(c.Options, c.connID, MethodCloseConn, "", nil)
-	ctx := before(c.Hooks, context.Background(), evt)
+	evt := newEvent(c.Options, c.meta, MethodCloseConn, "", nil)
+	ctx := before(c.Options, context.Background(), evt)
 	defer func() {
 		evt.Err = err
-		after(c.Hooks, ctx, evt)
+		after(c.Options, ctx, evt)
 	}()
 
-	return c.Conn.Close()
+	return intercept(c.Options, ctx, evt, func(context.Context) error {
+		return c.Conn.Close()
+	})
 }
 
 func (c otConn) ResetSession(ctx context.Context) (err error) {
+	// database/sql doesn't reset session of conn without driver.SessionResetter.
+	if _, ok := c.Conn.(driver.SessionResetter); !ok {
+		return nil
+	}
+	if !c.ResetSessionB && len(c.Interceptors) == 0 {
+		return c.resetSession(ctx)
+	}
+
+	evt := newEvent(c.Options, c.meta, MethodResetSession, "", nil)
 	if c.ResetSessionB {
-		evt := newEvent(c.Options, c.connID, MethodResetSession, "", nil)
-		ctx = before(c.Hooks, ctx, evt)
+		ctx = before(c.Options, ctx, evt)
 		defer func() {
 			evt.Err = err
-			after(c.Hooks, ctx, evt)
+			after(c.Options, ctx, evt)
 		}()
 	}
 
-	if cr, ok := c.Conn.(driver.SessionResetter); ok {
-		return cr.ResetSession(ctx)
+	return intercept(c.Options, ctx, evt, c.resetSession)
+}
+
+func (c otConn) resetSession(ctx context.Context) error {
+	return c.Conn.(driver.SessionResetter).ResetSession(ctx)
+}
+
+// CheckNamedValue returns driver.ErrSkip if conn doesn't implement
+// driver.NamedValueChecker, then database/sql converts the value by default.
+func (c otConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// IsValid returns true if conn doesn't implement driver.Validator,
+// the same as database/sql assumes.
+func (c otConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// values returns the arguments a hook or interceptor left in evt.Args,
+// falling back to args if evt.Args is not a []driver.Value.
+func values(evt *Event, args []driver.Value) []driver.Value {
+	if v, ok := evt.Args.([]driver.Value); ok {
+		return v
+	}
+	return args
+}
+
+// namedValues returns the arguments a hook or interceptor left in evt.Args,
+// falling back to args if evt.Args is not a []driver.NamedValue.
+func namedValues(evt *Event, args []driver.NamedValue) []driver.NamedValue {
+	if v, ok := evt.Args.([]driver.NamedValue); ok {
+		return v
 	}
+	return args
+}
+
+// connUnwrapper exposes otConn.Unwrap in the composition of wrapConn.
+type connUnwrapper interface {
+	Unwrap() driver.Conn
+}
+
+// Unwrap returns the driver.Conn wrapped by otsql.
+func (c otConn) Unwrap() driver.Conn {
+	return c.Conn
+}
+
+// connAll is implemented by otConn, which supports every optional interface
+// no matter what the wrapped driver.Conn supports.
+// driver.ConnPrepareContext, driver.ConnBeginTx, driver.SessionResetter,
+// driver.NamedValueChecker and driver.Validator are always exposed,
+// otConn falls back to what database/sql does without them.
+type connAll interface {
+	driver.Conn
+	driver.ConnPrepareContext
+	driver.ConnBeginTx
+	driver.SessionResetter
+	driver.NamedValueChecker
+	driver.Validator
+	connUnwrapper
+	driver.Pinger
+	driver.Execer // nolint
+	driver.ExecerContext
+	driver.Queryer // nolint
+	driver.QueryerContext
+}
+
+type connFlag int
+
+const (
+	connPinger connFlag = 1 << iota
+	connExecer
+	connExecerContext
+	connQueryer
+	connQueryerContext
+)
 
-	return nil
+func connFlags(conn driver.Conn) connFlag {
+	var flags connFlag
+	if _, ok := conn.(driver.Pinger); ok {
+		flags |= connPinger
+	}
+	if _, ok := conn.(driver.Execer); ok { // nolint
+		flags |= connExecer
+	}
+	if _, ok := conn.(driver.ExecerContext); ok {
+		flags |= connExecerContext
+	}
+	if _, ok := conn.(driver.Queryer); ok { // nolint
+		flags |= connQueryer
+	}
+	if _, ok := conn.(driver.QueryerContext); ok {
+		flags |= connQueryerContext
+	}
+	return flags
 }
 
-func wrapConn(connID string, conn driver.Conn, o *Options) driver.Conn {
-	return otConn{
+// wrapConn returns otConn which exposes the same optional interfaces
+// of execution and ping as conn, see connAll.
+func wrapConn(meta *connMeta, conn driver.Conn, o *Options) driver.Conn {
+	c := otConn{
 		Conn:    conn,
 		Options: o,
-		connID:  connID,
+		meta:    meta,
 	}
+	return composeConn(c, connFlags(conn))
 }
 
 // driver.Result
 type otResult struct {
 	driver.Result
 	*Options
-	ctx    context.Context
-	connID string
+	ctx  context.Context
+	meta *connMeta
 }
 
 func (r otResult) LastInsertId() (id int64, err error) {
-	if !r.LastInsertIdB {
+	if !r.LastInsertIdB && len(r.Interceptors) == 0 {
 		return r.Result.LastInsertId()
 	}
 
-	evt := newEvent(r.Options, r.connID, MethodLastInsertId, "", nil)
-	r.ctx = before(r.Hooks, r.ctx, evt)
-	defer func() {
-		evt.Err = err
-		after(r.Hooks, r.ctx, evt)
-	}()
+	evt := newEvent(r.Options, r.meta, MethodLastInsertId, "", nil)
+	if r.LastInsertIdB {
+		r.ctx = before(r.Options, r.ctx, evt)
+		defer func() {
+			evt.Err = err
+			after(r.Options, r.ctx, evt)
+		}()
+	}
 
-	id, err = r.Result.LastInsertId()
+	err = intercept(r.Options, r.ctx, evt, func(context.Context) error {
+		id, err := r.Result.LastInsertId()
+		evt.Result = id
+		return err
+	})
+	id, _ = evt.Result.(int64)
 	return
 }
 
 func (r otResult) RowsAffected() (cnt int64, err error) {
-	if !r.RowsAffectedB {
+	if !r.RowsAffectedB && len(r.Interceptors) == 0 {
 		return r.Result.RowsAffected()
 	}
 
-	evt := newEvent(r.Options, r.connID, MethodRowsAffected, "", nil)
-	r.ctx = before(r.Hooks, r.ctx, evt)
-	defer func() {
-		evt.Err = err
-		after(r.Hooks, r.ctx, evt)
-	}()
+	evt := newEvent(r.Options, r.meta, MethodRowsAffected, "", nil)
+	if r.RowsAffectedB {
+		r.ctx = before(r.Options, r.ctx, evt)
+		defer func() {
+			evt.Err = err
+			after(r.Options, r.ctx, evt)
+		}()
+	}
 
-	cnt, err = r.Result.RowsAffected()
+	err = intercept(r.Options, r.ctx, evt, func(context.Context) error {
+		cnt, err := r.Result.RowsAffected()
+		evt.Result = cnt
+		return err
+	})
+	cnt, _ = evt.Result.(int64)
 	return
 }
 
-func wrapResult(ctx context.Context, connID string, parent driver.Result, o *Options) driver.Result {
+// Unwrap returns the driver.Result wrapped by otsql.
+func (r otResult) Unwrap() driver.Result {
+	return r.Result
+}
+
+// captureResult reads RowsAffected and LastInsertId of a successful exec
+// into evt before After hooks run, if Options.ExecResultB is set.
+// Errors are ignored since drivers may not support either of them.
+func captureResult(evt *Event, res driver.Result, o *Options) {
+	if !o.ExecResultB || res == nil {
+		return
+	}
+	if n, err := res.RowsAffected(); err == nil {
+		evt.RowsAffected = &n
+	}
+	if id, err := res.LastInsertId(); err == nil {
+		evt.LastInsertID = &id
+	}
+}
+
+func wrapResult(ctx context.Context, meta *connMeta, parent driver.Result, o *Options) driver.Result {
 	return &otResult{
 		Result:  parent,
 		ctx:     ctx,
-		connID:  connID,
+		meta:    meta,
 		Options: o,
 	}
 }
 
-// withRowsColumnTypeScanType is the same as the driver.RowsColumnTypeScanType
-// interface except it omits the driver.Rows embedded interface.
-// If the original driver.Rows implementation wrapped by ocsql supports
-// RowsColumnTypeScanType we enable the original method implementation in the
-// returned driver.Rows from wrapRows by doing a composition with ocRows.
+// The optional interfaces of driver.Rows, except they omit the driver.Rows
+// embedded interface.
+// If the original driver.Rows implementation wrapped by otsql supports
+// some of them, we enable the same ones in the returned driver.Rows from
+// wrapRows by doing a composition with otRows, see composeRows.
+type withRowsNextResultSet interface {
+	HasNextResultSet() bool
+	NextResultSet() error
+}
+
 type withRowsColumnTypeScanType interface {
 	ColumnTypeScanType(index int) reflect.Type
 }
 
+type withRowsColumnTypeDatabaseTypeName interface {
+	ColumnTypeDatabaseTypeName(index int) string
+}
+
+type withRowsColumnTypeLength interface {
+	ColumnTypeLength(index int) (length int64, ok bool)
+}
+
+type withRowsColumnTypeNullable interface {
+	ColumnTypeNullable(index int) (nullable, ok bool)
+}
+
+type withRowsColumnTypePrecisionScale interface {
+	ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool)
+}
+
+// rowsUnwrapper exposes otRows.Unwrap in the composition of wrapRows.
+type rowsUnwrapper interface {
+	Unwrap() driver.Rows
+}
+
+// rowsAll is implemented by otRows, which supports every optional interface
+// no matter what the wrapped driver.Rows supports.
+type rowsAll interface {
+	driver.Rows
+	rowsUnwrapper
+	withRowsNextResultSet
+	withRowsColumnTypeScanType
+	withRowsColumnTypeDatabaseTypeName
+	withRowsColumnTypeLength
+	withRowsColumnTypeNullable
+	withRowsColumnTypePrecisionScale
+}
+
+type rowsFlag int
+
+const (
+	rowsNextResultSet rowsFlag = 1 << iota
+	rowsColumnTypeScanType
+	rowsColumnTypeDatabaseTypeName
+	rowsColumnTypeLength
+	rowsColumnTypeNullable
+	rowsColumnTypePrecisionScale
+)
+
+func rowsFlags(rows driver.Rows) rowsFlag {
+	var flags rowsFlag
+	if _, ok := rows.(driver.RowsNextResultSet); ok {
+		flags |= rowsNextResultSet
+	}
+	if _, ok := rows.(driver.RowsColumnTypeScanType); ok {
+		flags |= rowsColumnTypeScanType
+	}
+	if _, ok := rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		flags |= rowsColumnTypeDatabaseTypeName
+	}
+	if _, ok := rows.(driver.RowsColumnTypeLength); ok {
+		flags |= rowsColumnTypeLength
+	}
+	if _, ok := rows.(driver.RowsColumnTypeNullable); ok {
+		flags |= rowsColumnTypeNullable
+	}
+	if _, ok := rows.(driver.RowsColumnTypePrecisionScale); ok {
+		flags |= rowsColumnTypePrecisionScale
+	}
+	return flags
+}
+
 // driver.Rows
 type otRows struct {
 	driver.Rows
 	*Options
-	ctx    context.Context
-	connID string
+	ctx   context.Context
+	meta  *connMeta
+	stats *rowsStats
+}
+
+// rowsStats summarizes fetching of rows, it is shared by copies of otRows.
+// database/sql never calls Next and Close concurrently.
+type rowsStats struct {
+	now func() time.Time
+
+	// startAt is when the driver returned rows.
+	startAt    time.Time
+	firstRowAt time.Time
+	// endAt is when Next returned io.EOF, an error, or Close is called.
+	endAt time.Time
+	rows  int64
+
+	// onDone is called once endAt is set, err is what Next returned, nil if
+	// rows is closed first.
+	onDone func(err error)
+}
+
+func (s *rowsStats) next(err error) {
+	if err == nil {
+		if s.rows == 0 {
+			s.firstRowAt = s.now()
+		}
+		s.rows++
+		return
+	}
+	s.done(err)
+}
+
+func (s *rowsStats) done(err error) {
+	if !s.endAt.IsZero() {
+		return
+	}
+	s.endAt = s.now()
+	if s.onDone != nil {
+		s.onDone(err)
+	}
+}
+
+func (s *rowsStats) fill(evt *Event) {
+	evt.RowsReturned = s.rows
+	if s.rows > 0 {
+		evt.FirstRowLatency = s.firstRowAt.Sub(s.startAt)
+	}
+	evt.FetchDuration = s.endAt.Sub(s.startAt)
 }
 
 func (r otRows) Columns() []string {
@@ -289,237 +689,414 @@ func (r otRows) Columns() []string {
 }
 
 func (r otRows) Close() (err error) {
-	if !r.RowsCloseB {
+	r.stats.done(nil)
+	if !r.RowsCloseB && len(r.Interceptors) == 0 {
 		return r.Rows.Close()
 	}
 
-	evt := newEvent(r.Options, r.connID, MethodRowsClose, "", nil)
-	r.ctx = before(r.Hooks, r.ctx, evt)
-	defer func() {
-		evt.Err = err
-		after(r.Hooks, r.ctx, evt)
-	}()
+	evt := newEvent(r.Options, r.meta, MethodRowsClose, "", nil)
+	r.stats.fill(evt)
+	if r.RowsCloseB {
+		r.ctx = before(r.Options, r.ctx, evt)
+		defer func() {
+			evt.Err = err
+			after(r.Options, r.ctx, evt)
+		}()
+	}
 
-	return r.Rows.Close()
+	return intercept(r.Options, r.ctx, evt, func(context.Context) error {
+		return r.Rows.Close()
+	})
 }
 
 func (r otRows) Next(dest []driver.Value) (err error) {
-	if !r.RowsNextB {
-		return r.Rows.Next(dest)
+	hooked := CallOptionsFromContext(r.ctx).RowsNext(r.RowsNextB)
+	if !hooked && len(r.Interceptors) == 0 {
+		err = r.Rows.Next(dest)
+		r.stats.next(err)
+		return err
 	}
 
-	evt := newEvent(r.Options, r.connID, MethodRowsNext, "", nil)
-	r.ctx = before(r.Hooks, r.ctx, evt)
+	evt := newEvent(r.Options, r.meta, MethodRowsNext, "", nil)
+	if hooked {
+		r.ctx = before(r.Options, r.ctx, evt)
+	}
+	defer func() {
+		r.stats.next(err)
+		if hooked {
+			evt.Err = err
+			after(r.Options, r.ctx, evt)
+		}
+	}()
+
+	return intercept(r.Options, r.ctx, evt, func(context.Context) error {
+		return r.Rows.Next(dest)
+	})
+}
+
+func (r otRows) HasNextResultSet() bool {
+	return r.Rows.(driver.RowsNextResultSet).HasNextResultSet()
+}
+
+func (r otRows) NextResultSet() (err error) {
+	evt := newEvent(r.Options, r.meta, MethodRowsNextResultSet, "", nil)
+	r.ctx = before(r.Options, r.ctx, evt)
 	defer func() {
 		evt.Err = err
-		after(r.Hooks, r.ctx, evt)
+		after(r.Options, r.ctx, evt)
 	}()
 
-	err = r.Rows.Next(dest)
-	return
+	return intercept(r.Options, r.ctx, evt, func(context.Context) error {
+		return r.Rows.(driver.RowsNextResultSet).NextResultSet()
+	})
+}
+
+// The methods of column type only pass through, composeRows guarantees they
+// are exposed only if the wrapped driver.Rows supports them.
+
+func (r otRows) ColumnTypeScanType(index int) reflect.Type {
+	return r.Rows.(driver.RowsColumnTypeScanType).ColumnTypeScanType(index)
+}
+
+func (r otRows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.Rows.(driver.RowsColumnTypeDatabaseTypeName).ColumnTypeDatabaseTypeName(index)
+}
+
+func (r otRows) ColumnTypeLength(index int) (int64, bool) {
+	return r.Rows.(driver.RowsColumnTypeLength).ColumnTypeLength(index)
+}
+
+func (r otRows) ColumnTypeNullable(index int) (bool, bool) {
+	return r.Rows.(driver.RowsColumnTypeNullable).ColumnTypeNullable(index)
+}
+
+func (r otRows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	return r.Rows.(driver.RowsColumnTypePrecisionScale).ColumnTypePrecisionScale(index)
+}
+
+// Unwrap returns the driver.Rows wrapped by otsql.
+func (r otRows) Unwrap() driver.Rows {
+	return r.Rows
 }
 
-func wrapRows(ctx context.Context, connID string, parent driver.Rows, o *Options) driver.Rows {
-	ts, isColumnTypeScan := parent.(driver.RowsColumnTypeScanType)
+// wrapRows wraps rows returned by the query described by event query,
+// which is finished by rows if Options.DeferQueryEndB is set.
+func wrapRows(ctx context.Context, meta *connMeta, parent driver.Rows, query *Event, o *Options) driver.Rows {
+	stats := &rowsStats{now: o.now, startAt: o.now()}
+	if o.DeferQueryEndB && query != nil {
+		stats.onDone = func(err error) {
+			if err != io.EOF {
+				query.Err = err
+			}
+			stats.fill(query)
+			query.RowsAt, query.EndAt = stats.startAt, stats.endAt
+			after(o, ctx, query)
+		}
+	}
+
 	r := otRows{
 		Rows:    parent,
 		ctx:     ctx,
-		connID:  connID,
+		meta:    meta,
+		stats:   stats,
 		Options: o,
 	}
-	if isColumnTypeScan {
-		return struct {
-			otRows
-			withRowsColumnTypeScanType
-		}{r, ts}
-	}
-
-	return r
+	return composeRows(r, rowsFlags(parent))
 }
 
 type otStmt struct {
 	driver.Stmt
-	query  string
-	connID string
+	query string
+	// info is shared by events of the statement.
+	info *queryInfo
+	meta *connMeta
 	*Options
+
+	id string
+	// prepare is the copy of event of Prepare, see Event.Prepare.
+	prepare *Event
+	// executions counts calls through the statement, shared by copies of otStmt.
+	executions *int64
+}
+
+func (s otStmt) newEvent(method Method, args interface{}) *Event {
+	evt := newEvent(s.Options, s.meta, method, s.query, args)
+	evt.Prepared = true
+	evt.info = s.info
+	evt.StmtID = s.id
+	evt.StmtExecutions = atomic.AddInt64(s.executions, 1)
+	evt.Prepare = s.prepare
+	return evt
 }
 
 func (s otStmt) Exec(args []driver.Value) (res driver.Result, err error) {
-	evt := newEvent(s.Options, s.connID, MethodExec, s.query, args)
-	ctx := before(s.Hooks, context.Background(), evt)
+	evt := s.newEvent(MethodExec, args)
+	ctx := before(s.Options, context.Background(), evt)
 	defer func() {
+		if skipped(err) {
+			return
+		}
 		evt.Err = err
-		after(s.Hooks, ctx, evt)
+		after(s.Options, ctx, evt)
 	}()
 
-	res, err = s.Stmt.Exec(args) // nolint
-	if err != nil {
+	if err = intercept(s.Options, ctx, evt, func(context.Context) error {
+		res, err := s.Stmt.Exec(values(evt, args)) // nolint
+		evt.Result = res
+		return err
+	}); err != nil {
 		return nil, err
 	}
-	return wrapResult(ctx, s.connID, res, s.Options), nil
+	res = evt.Result.(driver.Result)
+	captureResult(evt, res, s.Options)
+	return wrapResult(ctx, s.meta, res, s.Options), nil
 }
 
 func (s otStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (res driver.Result, err error) {
-	evt := newEvent(s.Options, s.connID, MethodExec, s.query, args)
-	ctx = before(s.Hooks, ctx, evt)
+	evt := s.newEvent(MethodExec, args)
+	ctx = before(s.Options, ctx, evt)
 	defer func() {
+		if skipped(err) {
+			return
+		}
 		evt.Err = err
-		after(s.Hooks, ctx, evt)
+		after(s.Options, ctx, evt)
 	}()
 
-	// we already tested driver when wrap stmt
-	res, err = s.Stmt.(driver.StmtExecContext).ExecContext(ctx, args)
-	if err != nil {
+	if err = intercept(s.Options, ctx, evt, func(ctx context.Context) error {
+		// we already tested driver when wrap stmt
+		res, err := s.Stmt.(driver.StmtExecContext).ExecContext(ctx, namedValues(evt, args))
+		evt.Result = res
+		return err
+	}); err != nil {
 		return nil, err
 	}
-	return wrapResult(ctx, s.connID, res, s.Options), nil
+	res = evt.Result.(driver.Result)
+	captureResult(evt, res, s.Options)
+	return wrapResult(ctx, s.meta, res, s.Options), nil
 }
 
 func (s otStmt) Query(args []driver.Value) (rows driver.Rows, err error) {
-	evt := newEvent(s.Options, s.connID, MethodQuery, s.query, args)
-	ctx := before(s.Hooks, context.Background(), evt)
+	evt := s.newEvent(MethodQuery, args)
+	ctx := before(s.Options, context.Background(), evt)
 	defer func() {
+		if skipped(err) {
+			return
+		}
+		// finished by rows, see Options.DeferQueryEndB.
+		if err == nil && s.DeferQueryEndB {
+			return
+		}
 		evt.Err = err
-		after(s.Hooks, ctx, evt)
+		after(s.Options, ctx, evt)
 	}()
 
-	rows, err = s.Stmt.Query(args) // nolint
-	if err != nil {
+	if err = intercept(s.Options, ctx, evt, func(context.Context) error {
+		rows, err := s.Stmt.Query(values(evt, args)) // nolint
+		evt.Result = rows
+		return err
+	}); err != nil {
 		return nil, err
 	}
-	return wrapRows(ctx, s.connID, rows, s.Options), nil
+	rows = evt.Result.(driver.Rows)
+	return wrapRows(ctx, s.meta, rows, evt, s.Options), nil
 }
 
 func (s otStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (rows driver.Rows, err error) {
-	evt := newEvent(s.Options, s.connID, MethodQuery, s.query, args)
-	ctx = before(s.Hooks, ctx, evt)
+	evt := s.newEvent(MethodQuery, args)
+	ctx = before(s.Options, ctx, evt)
 	defer func() {
+		if skipped(err) {
+			return
+		}
+		// finished by rows, see Options.DeferQueryEndB.
+		if err == nil && s.DeferQueryEndB {
+			return
+		}
 		evt.Err = err
-		after(s.Hooks, ctx, evt)
+		after(s.Options, ctx, evt)
 	}()
 
-	// we already tested driver when wrap stmt
-	rows, err = s.Stmt.(driver.StmtQueryContext).QueryContext(ctx, args)
-	if err != nil {
+	if err = intercept(s.Options, ctx, evt, func(ctx context.Context) error {
+		// we already tested driver when wrap stmt
+		rows, err := s.Stmt.(driver.StmtQueryContext).QueryContext(ctx, namedValues(evt, args))
+		evt.Result = rows
+		return err
+	}); err != nil {
 		return nil, err
 	}
-	return wrapRows(ctx, s.connID, rows, s.Options), nil
+	rows = evt.Result.(driver.Rows)
+	return wrapRows(ctx, s.meta, rows, evt, s.Options), nil
+}
+
+func (s otStmt) Close() (err error) {
+	if !s.StmtCloseB && len(s.Interceptors) == 0 {
+		return s.Stmt.Close()
+	}
+
+	evt := newEvent(s.Options, s.meta, MethodStmtClose, s.query, nil)
+	evt.Prepared = true
+	evt.StmtID = s.id
+	evt.Prepare = s.prepare
+	ctx := context.Background()
+	if s.StmtCloseB {
+		ctx = before(s.Options, ctx, evt)
+		defer func() {
+			evt.Err = err
+			after(s.Options, ctx, evt)
+		}()
+	}
+
+	return intercept(s.Options, ctx, evt, func(context.Context) error {
+		return s.Stmt.Close()
+	})
+}
+
+// stmtUnwrapper exposes otStmt.Unwrap in the composition of wrapStmt.
+type stmtUnwrapper interface {
+	Unwrap() driver.Stmt
 }
 
-func wrapStmt(connID string, stmt driver.Stmt, query string, o *Options) driver.Stmt {
+// Unwrap returns the driver.Stmt wrapped by otsql.
+func (s otStmt) Unwrap() driver.Stmt {
+	return s.Stmt
+}
+
+// wrapStmt wraps stmt prepared by evt.
+func wrapStmt(meta *connMeta, stmt driver.Stmt, evt *Event, o *Options) driver.Stmt {
 	_, isExecCtx := stmt.(driver.StmtExecContext)
 	_, isQueryCtx := stmt.(driver.StmtQueryContext)
 	cc, isColumnConverter := stmt.(driver.ColumnConverter) // nolint
 	nvc, isNamedValueChecker := stmt.(driver.NamedValueChecker)
 
 	s := otStmt{
-		connID:  connID,
-		Stmt:    stmt,
-		query:   query,
-		Options: o,
+		meta:       meta,
+		Stmt:       stmt,
+		query:      evt.Query,
+		info:       evt.info,
+		Options:    o,
+		id:         evt.StmtID,
+		prepare:    evt.prepared(),
+		executions: new(int64),
 	}
 
 	switch {
 	case !isExecCtx && !isQueryCtx && !isColumnConverter && !isNamedValueChecker:
 		return struct {
 			driver.Stmt
-		}{s}
+			stmtUnwrapper
+		}{s, s}
 
 	case isExecCtx && !isQueryCtx && !isColumnConverter && !isNamedValueChecker:
 		return struct {
 			driver.Stmt
+			stmtUnwrapper
 			driver.StmtExecContext
-		}{s, s}
+		}{s, s, s}
 	case !isExecCtx && isQueryCtx && !isColumnConverter && !isNamedValueChecker:
 		return struct {
 			driver.Stmt
+			stmtUnwrapper
 			driver.StmtQueryContext
-		}{s, s}
+		}{s, s, s}
 	case !isExecCtx && !isQueryCtx && isColumnConverter && !isNamedValueChecker:
 		return struct {
 			driver.Stmt
+			stmtUnwrapper
 			driver.ColumnConverter
-		}{s, cc}
+		}{s, s, cc}
 	case !isExecCtx && !isQueryCtx && !isColumnConverter && isNamedValueChecker:
 		return struct {
 			driver.Stmt
+			stmtUnwrapper
 			driver.NamedValueChecker
-		}{s, nvc}
+		}{s, s, nvc}
 
 	case isExecCtx && isQueryCtx && !isColumnConverter && !isNamedValueChecker:
 		return struct {
 			driver.Stmt
+			stmtUnwrapper
 			driver.StmtExecContext
 			driver.StmtQueryContext
-		}{s, s, s}
+		}{s, s, s, s}
 	case isExecCtx && !isQueryCtx && isColumnConverter && !isNamedValueChecker:
 		return struct {
 			driver.Stmt
+			stmtUnwrapper
 			driver.StmtExecContext
 			driver.ColumnConverter
-		}{s, s, cc}
+		}{s, s, s, cc}
 	case isExecCtx && !isQueryCtx && !isColumnConverter && isNamedValueChecker:
 		return struct {
 			driver.Stmt
+			stmtUnwrapper
 			driver.StmtExecContext
 			driver.NamedValueChecker
-		}{s, s, nvc}
+		}{s, s, s, nvc}
 	case !isExecCtx && isQueryCtx && isColumnConverter && !isNamedValueChecker:
 		return struct {
 			driver.Stmt
+			stmtUnwrapper
 			driver.StmtQueryContext
 			driver.ColumnConverter
-		}{s, s, cc}
+		}{s, s, s, cc}
 	case !isExecCtx && isQueryCtx && !isColumnConverter && isNamedValueChecker:
 		return struct {
 			driver.Stmt
+			stmtUnwrapper
 			driver.StmtQueryContext
 			driver.NamedValueChecker
-		}{s, s, nvc}
+		}{s, s, s, nvc}
 	case !isExecCtx && !isQueryCtx && isColumnConverter && isNamedValueChecker:
 		return struct {
 			driver.Stmt
+			stmtUnwrapper
 			driver.ColumnConverter
 			driver.NamedValueChecker
-		}{s, cc, nvc}
+		}{s, s, cc, nvc}
 
 	case isExecCtx && isQueryCtx && isColumnConverter && !isNamedValueChecker:
 		return struct {
 			driver.Stmt
+			stmtUnwrapper
 			driver.StmtExecContext
 			driver.StmtQueryContext
 			driver.ColumnConverter
-		}{s, s, s, cc}
+		}{s, s, s, s, cc}
 	case isExecCtx && isQueryCtx && !isColumnConverter && isNamedValueChecker:
 		return struct {
 			driver.Stmt
+			stmtUnwrapper
 			driver.StmtExecContext
 			driver.StmtQueryContext
 			driver.NamedValueChecker
-		}{s, s, s, nvc}
+		}{s, s, s, s, nvc}
 	case isExecCtx && !isQueryCtx && isColumnConverter && isNamedValueChecker:
 		return struct {
 			driver.Stmt
+			stmtUnwrapper
 			driver.StmtExecContext
 			driver.ColumnConverter
 			driver.NamedValueChecker
-		}{s, s, cc, nvc}
+		}{s, s, s, cc, nvc}
 	case !isExecCtx && isQueryCtx && isColumnConverter && isNamedValueChecker:
 		return struct {
 			driver.Stmt
+			stmtUnwrapper
 			driver.StmtQueryContext
 			driver.ColumnConverter
 			driver.NamedValueChecker
-		}{s, s, cc, nvc}
+		}{s, s, s, cc, nvc}
 
 	case isExecCtx && isQueryCtx && isColumnConverter && isNamedValueChecker:
 		return struct {
 			driver.Stmt
+			stmtUnwrapper
 			driver.StmtExecContext
 			driver.StmtQueryContext
 			driver.ColumnConverter
 			driver.NamedValueChecker
-		}{s, s, s, cc, nvc}
+		}{s, s, s, s, cc, nvc}
 	}
 
 	panic("unreachable")
@@ -527,38 +1104,49 @@ func wrapStmt(connID string, stmt driver.Stmt, query string, o *Options) driver.
 
 type otTx struct {
 	driver.Tx
-	ctx    context.Context
-	connID string
+	ctx  context.Context
+	meta *connMeta
 	*Options
 }
 
 func (t otTx) Commit() (err error) {
-	evt := newEvent(t.Options, t.connID, MethodCommit, "", nil)
-	ctx := before(t.Hooks, context.Background(), evt)
+	evt := newEvent(t.Options, t.meta, MethodCommit, "", nil)
+	ctx := before(t.Options, t.ctx, evt)
 	defer func() {
 		evt.Err = err
-		after(t.Hooks, ctx, evt)
+		t.meta.endTx()
+		after(t.Options, ctx, evt)
 	}()
 
-	return t.Tx.Commit()
+	return intercept(t.Options, ctx, evt, func(context.Context) error {
+		return t.Tx.Commit()
+	})
 }
 
 func (t otTx) Rollback() (err error) {
-	evt := newEvent(t.Options, t.connID, MethodRollback, "", nil)
-	ctx := before(t.Hooks, context.Background(), evt)
+	evt := newEvent(t.Options, t.meta, MethodRollback, "", nil)
+	ctx := before(t.Options, t.ctx, evt)
 	defer func() {
 		evt.Err = err
-		after(t.Hooks, ctx, evt)
+		t.meta.endTx()
+		after(t.Options, ctx, evt)
 	}()
 
-	return t.Tx.Rollback()
+	return intercept(t.Options, ctx, evt, func(context.Context) error {
+		return t.Tx.Rollback()
+	})
+}
+
+// Unwrap returns the driver.Tx wrapped by otsql.
+func (t otTx) Unwrap() driver.Tx {
+	return t.Tx
 }
 
-func wrapTx(ctx context.Context, connID string, tx driver.Tx, o *Options) driver.Tx {
+func wrapTx(ctx context.Context, meta *connMeta, tx driver.Tx, o *Options) driver.Tx {
 	return otTx{
 		Tx:      tx,
 		ctx:     ctx,
-		connID:  connID,
+		meta:    meta,
 		Options: o,
 	}
 }
@@ -0,0 +1,542 @@
+package otsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeConn struct {
+	query string
+	args  []driver.NamedValue
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	c.query = query
+	return &fakeStmt{conn: c}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+func (c *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.query, c.args = query, args
+	return driver.RowsAffected(1), nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error { return nil }
+
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	conn *fakeConn
+}
+
+func (s *fakeStmt) Close() error { return nil }
+
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, driver.ErrSkip
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, driver.ErrSkip
+}
+
+func (s *fakeStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	s.conn.args = args
+	return driver.RowsAffected(1), nil
+}
+
+type rewriteHook struct{}
+
+func (rewriteHook) Before(ctx context.Context, evt *Event) context.Context {
+	evt.Query += " /* rewritten */"
+	if args, ok := evt.Args.([]driver.NamedValue); ok {
+		evt.Args = append(args, driver.NamedValue{Ordinal: len(args) + 1, Value: "tenant"})
+	}
+	return ctx
+}
+
+func (rewriteHook) After(ctx context.Context, evt *Event) {}
+
+func TestRewriteQueryAndArgs(t *testing.T) {
+	ctx := context.Background()
+	fc := &fakeConn{}
+	conn := WrapConn(fc, WithHooks(rewriteHook{}))
+
+	args := []driver.NamedValue{{Ordinal: 1, Value: 1}}
+	_, err := conn.(driver.ExecerContext).ExecContext(ctx, "UPDATE t SET a = ?", args)
+	require.NoError(t, err)
+	require.Equal(t, "UPDATE t SET a = ? /* rewritten */", fc.query)
+	require.Len(t, fc.args, 2)
+
+	stmt, err := conn.(driver.ConnPrepareContext).PrepareContext(ctx, "DELETE FROM t WHERE a = ?")
+	require.NoError(t, err)
+	require.Equal(t, "DELETE FROM t WHERE a = ? /* rewritten */", fc.query)
+
+	_, err = stmt.(driver.StmtExecContext).ExecContext(ctx, args)
+	require.NoError(t, err)
+	require.Equal(t, []driver.NamedValue{{Ordinal: 1, Value: 1}, {Ordinal: 2, Value: "tenant"}}, fc.args)
+}
+
+type recordHook struct {
+	events []*Event
+	ctxs   []context.Context
+}
+
+func (h *recordHook) Before(ctx context.Context, evt *Event) context.Context {
+	return ctx
+}
+
+func (h *recordHook) After(ctx context.Context, evt *Event) {
+	h.events = append(h.events, evt)
+	h.ctxs = append(h.ctxs, ctx)
+}
+
+type ctxKey struct{}
+
+func TestTxID(t *testing.T) {
+	hook := &recordHook{}
+	conn := WrapConn(&fakeConn{}, WithHooks(hook))
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "begin")
+	opts := driver.TxOptions{ReadOnly: true}
+	tx, err := conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+	require.NoError(t, err)
+	_, err = conn.(driver.ExecerContext).ExecContext(ctx, "UPDATE t SET a = 1", nil)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	_, err = conn.(driver.ExecerContext).ExecContext(ctx, "UPDATE t SET a = 1", nil)
+	require.NoError(t, err)
+
+	require.Len(t, hook.events, 4)
+	begin, exec, commit, outside := hook.events[0], hook.events[1], hook.events[2], hook.events[3]
+	require.NotEmpty(t, begin.TxID)
+	require.Equal(t, &opts, begin.TxOptions)
+	require.Equal(t, begin.TxID, exec.TxID)
+	require.Equal(t, begin.TxID, commit.TxID)
+	require.Empty(t, outside.TxID)
+	require.Nil(t, outside.TxOptions)
+	require.Equal(t, begin.Conn, outside.Conn)
+	require.Equal(t, "begin", hook.ctxs[2].Value(ctxKey{}))
+}
+
+func TestExecResult(t *testing.T) {
+	hook := &recordHook{}
+	conn := WrapConn(&fakeConn{}, WithHooks(hook), WithExecResult(true))
+
+	_, err := conn.(driver.ExecerContext).ExecContext(context.Background(), "UPDATE t SET a = 1", nil)
+	require.NoError(t, err)
+
+	require.Len(t, hook.events, 1)
+	evt := hook.events[0]
+	require.NotNil(t, evt.RowsAffected)
+	require.Equal(t, int64(1), *evt.RowsAffected)
+	// driver.RowsAffected does not support LastInsertId.
+	require.Nil(t, evt.LastInsertID)
+}
+
+type fakeRows struct {
+	nextResultSet int
+	remaining     int
+}
+
+// Unwrap lets fakeRows satisfy rowsAll, so composeRows can build rows with any
+// combination of optional interfaces.
+func (r *fakeRows) Unwrap() driver.Rows { return nil }
+
+func (r *fakeRows) Columns() []string { return []string{"id"} }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.remaining == 0 {
+		return io.EOF
+	}
+	r.remaining--
+	return nil
+}
+
+func (r *fakeRows) HasNextResultSet() bool { return r.nextResultSet == 0 }
+
+func (r *fakeRows) NextResultSet() error {
+	r.nextResultSet++
+	return nil
+}
+
+func (r *fakeRows) ColumnTypeScanType(index int) reflect.Type { return reflect.TypeOf(int64(0)) }
+
+func (r *fakeRows) ColumnTypeDatabaseTypeName(index int) string { return "BIGINT" }
+
+func (r *fakeRows) ColumnTypeLength(index int) (int64, bool) { return 8, true }
+
+func (r *fakeRows) ColumnTypeNullable(index int) (bool, bool) { return true, true }
+
+func (r *fakeRows) ColumnTypePrecisionScale(index int) (int64, int64, bool) { return 10, 2, true }
+
+func TestWrapRows(t *testing.T) {
+	for flags := rowsFlag(0); flags < 1<<6; flags++ {
+		hook := &recordHook{}
+		parent := composeRows(&fakeRows{}, flags)
+		rows := wrapRows(context.Background(), newConnMeta(), parent, nil, newOptions([]Option{WithHooks(hook)}))
+
+		require.Equal(t, flags, rowsFlags(rows), "flags %b", flags)
+
+		if rs, ok := rows.(driver.RowsNextResultSet); ok {
+			require.True(t, rs.HasNextResultSet())
+			require.NoError(t, rs.NextResultSet())
+			require.False(t, rs.HasNextResultSet())
+			require.Len(t, hook.events, 1)
+			require.Equal(t, MethodRowsNextResultSet, hook.events[0].Method)
+		}
+		if ct, ok := rows.(driver.RowsColumnTypeScanType); ok {
+			require.Equal(t, reflect.TypeOf(int64(0)), ct.ColumnTypeScanType(0))
+		}
+		if ct, ok := rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+			require.Equal(t, "BIGINT", ct.ColumnTypeDatabaseTypeName(0))
+		}
+		if ct, ok := rows.(driver.RowsColumnTypeLength); ok {
+			length, ok := ct.ColumnTypeLength(0)
+			require.True(t, ok)
+			require.Equal(t, int64(8), length)
+		}
+		if ct, ok := rows.(driver.RowsColumnTypeNullable); ok {
+			nullable, ok := ct.ColumnTypeNullable(0)
+			require.True(t, ok)
+			require.True(t, nullable)
+		}
+		if ct, ok := rows.(driver.RowsColumnTypePrecisionScale); ok {
+			precision, scale, ok := ct.ColumnTypePrecisionScale(0)
+			require.True(t, ok)
+			require.Equal(t, int64(10), precision)
+			require.Equal(t, int64(2), scale)
+		}
+	}
+}
+
+func TestRowsStats(t *testing.T) {
+	hook := &recordHook{}
+	now := time.Unix(0, 0)
+	clock := func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	o := newOptions([]Option{WithHooks(hook), WithRowsClose(true), WithClock(clock)})
+	rows := wrapRows(context.Background(), newConnMeta(), &fakeRows{remaining: 3}, nil, o)
+
+	dest := make([]driver.Value, 1)
+	for rows.Next(dest) == nil {
+	}
+	require.NoError(t, rows.Close())
+
+	require.Len(t, hook.events, 1)
+	evt := hook.events[0]
+	require.Equal(t, MethodRowsClose, evt.Method)
+	require.Equal(t, int64(3), evt.RowsReturned)
+	// rows are returned at 1s, the first row is fetched at 2s and io.EOF at 3s.
+	require.Equal(t, time.Second, evt.FirstRowLatency)
+	require.Equal(t, 2*time.Second, evt.FetchDuration)
+}
+
+type fakeConnAll struct {
+	fakeConn
+}
+
+// Unwrap lets fakeConnAll satisfy connAll, see fakeRows.Unwrap.
+func (c *fakeConnAll) Unwrap() driver.Conn { return nil }
+
+func (c *fakeConnAll) Ping(ctx context.Context) error { return nil }
+
+func (c *fakeConnAll) Exec(query string, args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConnAll) Query(query string, args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{}, nil
+}
+
+func (c *fakeConnAll) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{}, nil
+}
+
+func (c *fakeConnAll) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.Prepare(query)
+}
+
+func (c *fakeConnAll) ResetSession(ctx context.Context) error { return nil }
+
+func (c *fakeConnAll) CheckNamedValue(nv *driver.NamedValue) error { return driver.ErrRemoveArgument }
+
+func (c *fakeConnAll) IsValid() bool { return false }
+
+func TestWrapConn(t *testing.T) {
+	for flags := connFlag(0); flags < 1<<5; flags++ {
+		parent := composeConn(&fakeConnAll{}, flags)
+		conn := WrapConn(parent)
+
+		require.Equal(t, flags, connFlags(conn), "flags %b", flags)
+		require.Equal(t, driver.ErrRemoveArgument, conn.(driver.NamedValueChecker).CheckNamedValue(&driver.NamedValue{}))
+		require.False(t, conn.(driver.Validator).IsValid())
+	}
+}
+
+func TestWrapConnFallback(t *testing.T) {
+	ctx := context.Background()
+	hook := &recordHook{}
+	conn := WrapConn(struct{ driver.Conn }{&fakeConn{}}, WithHooks(hook), WithResetSession(true))
+
+	require.Equal(t, driver.ErrSkip, conn.(driver.NamedValueChecker).CheckNamedValue(&driver.NamedValue{}))
+	require.True(t, conn.(driver.Validator).IsValid())
+	require.NoError(t, conn.(driver.SessionResetter).ResetSession(ctx))
+	require.Empty(t, hook.events)
+
+	beginner := conn.(driver.ConnBeginTx)
+	tx, err := beginner.BeginTx(ctx, driver.TxOptions{})
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	_, err = beginner.BeginTx(ctx, driver.TxOptions{ReadOnly: true})
+	require.Error(t, err)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = beginner.BeginTx(canceled, driver.TxOptions{})
+	require.Equal(t, context.Canceled, err)
+}
+
+func TestUnwrap(t *testing.T) {
+	ctx := context.Background()
+	fc := &fakeConn{}
+	conn := WrapConn(fc)
+	require.Equal(t, fc, UnwrapConn(conn))
+	require.Equal(t, fc, UnwrapConn(WrapConn(conn)))
+	require.Equal(t, fc, UnwrapConn(fc))
+
+	stmt, err := conn.(driver.ConnPrepareContext).PrepareContext(ctx, "SELECT 1")
+	require.NoError(t, err)
+	require.IsType(t, &fakeStmt{}, UnwrapStmt(stmt))
+
+	fr := &fakeRows{}
+	rows := wrapRows(ctx, newConnMeta(), struct{ driver.Rows }{fr}, nil, newOptions(nil))
+	require.Equal(t, struct{ driver.Rows }{fr}, UnwrapRows(rows))
+
+	dri := Wrap(fakeDriver{})
+	require.Equal(t, fakeDriver{}, UnwrapDriver(dri))
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) { return &fakeConn{}, nil }
+
+func TestEventDuration(t *testing.T) {
+	hook := &recordHook{}
+	now := time.Unix(0, 0)
+	clock := func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	conn := WrapConn(&fakeConn{}, WithHooks(hook), WithClock(clock))
+
+	_, err := conn.(driver.ExecerContext).ExecContext(context.Background(), "UPDATE t SET a = 1", nil)
+	require.NoError(t, err)
+
+	require.Len(t, hook.events, 1)
+	evt := hook.events[0]
+	require.Equal(t, time.Unix(1, 0), evt.BeginAt)
+	require.Equal(t, time.Unix(2, 0), evt.EndAt)
+	require.Equal(t, time.Second, evt.Duration)
+}
+
+func TestFingerprint(t *testing.T) {
+	hook := &recordHook{}
+	conn := WrapConn(&fakeConn{}, WithHooks(hook), WithFingerprint(true))
+
+	ctx := context.Background()
+	stmt, err := conn.(driver.ConnPrepareContext).PrepareContext(ctx, "DELETE FROM t WHERE a = ?")
+	require.NoError(t, err)
+	_, err = stmt.(driver.StmtExecContext).ExecContext(ctx, nil)
+	require.NoError(t, err)
+	_, err = conn.(driver.ExecerContext).ExecContext(ctx, "DELETE FROM t WHERE a = 1", nil)
+	require.NoError(t, err)
+
+	require.Len(t, hook.events, 3)
+	prepare, stmtExec, exec := hook.events[0], hook.events[1], hook.events[2]
+	// computed only when asked.
+	require.Nil(t, exec.info.fp)
+	require.Equal(t, "delete from t where a = ?", prepare.Fingerprint().Query)
+	require.Same(t, prepare.Fingerprint(), stmtExec.Fingerprint())
+	require.Equal(t, prepare.Fingerprint().Digest, exec.Fingerprint().Digest)
+	require.Equal(t, "DELETE", exec.Operation())
+	require.Equal(t, []string{"t"}, exec.Tables())
+}
+
+func TestDeferQueryEnd(t *testing.T) {
+	hook := &recordHook{}
+	now := time.Unix(0, 0)
+	clock := func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	conn := WrapConn(&fakeConnAll{}, WithHooks(hook), WithDeferQueryEnd(true), WithClock(clock))
+
+	rows, err := conn.(driver.QueryerContext).QueryContext(context.Background(), "SELECT 1", nil)
+	require.NoError(t, err)
+	require.Empty(t, hook.events)
+
+	require.Equal(t, io.EOF, rows.Next(nil))
+	require.NoError(t, rows.Close())
+
+	require.Len(t, hook.events, 1)
+	evt := hook.events[0]
+	require.Equal(t, MethodQuery, evt.Method)
+	require.Equal(t, time.Unix(1, 0), evt.BeginAt)
+	require.Equal(t, time.Unix(3, 0), evt.RowsAt)
+	require.Equal(t, time.Unix(4, 0), evt.EndAt)
+	require.Equal(t, 3*time.Second, evt.Duration)
+	require.Equal(t, time.Second, evt.FetchDuration)
+}
+
+type valueHook struct{}
+
+func (valueHook) Before(ctx context.Context, evt *Event) context.Context {
+	evt.SetValue(ctxKey{}, string(evt.Method))
+	return ctx
+}
+
+func (valueHook) After(ctx context.Context, evt *Event) {}
+
+func TestStmtID(t *testing.T) {
+	hook := &recordHook{}
+	conn := WrapConn(&fakeConn{}, WithHooks(valueHook{}, hook))
+
+	ctx := WithTags(context.Background(), "tenant", "j2gg0s")
+	stmt, err := conn.(driver.ConnPrepareContext).PrepareContext(ctx, "DELETE FROM t WHERE a = ?")
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = stmt.(driver.StmtExecContext).ExecContext(context.Background(), nil)
+		require.NoError(t, err)
+	}
+	other, err := conn.Prepare("DELETE FROM t WHERE a = ?")
+	require.NoError(t, err)
+	_, err = other.(driver.StmtExecContext).ExecContext(context.Background(), nil)
+	require.NoError(t, err)
+
+	require.Len(t, hook.events, 5)
+	prepare, first, second, otherPrepare, otherExec := hook.events[0], hook.events[1], hook.events[2], hook.events[3], hook.events[4]
+	require.NotEmpty(t, prepare.StmtID)
+	require.Equal(t, prepare.StmtID, first.StmtID)
+	require.Equal(t, prepare.StmtID, second.StmtID)
+	require.NotEqual(t, prepare.StmtID, otherPrepare.StmtID)
+	require.Equal(t, otherPrepare.StmtID, otherExec.StmtID)
+
+	require.Equal(t, int64(1), first.StmtExecutions)
+	require.Equal(t, int64(2), second.StmtExecutions)
+	require.Equal(t, int64(1), otherExec.StmtExecutions)
+	require.Same(t, first.Prepare, second.Prepare)
+	require.Equal(t, prepare.StmtID, first.Prepare.StmtID)
+	require.Equal(t, string(MethodPrepare), first.Prepare.Value(ctxKey{}))
+	// request-scoped data of Prepare is not kept by the statement.
+	require.NotEmpty(t, prepare.Tags)
+	require.Nil(t, first.Prepare.Tags)
+}
+
+func TestInterceptUnhookedCalls(t *testing.T) {
+	hook := &recordHook{}
+	var methods []Method
+	record := func(ctx context.Context, evt *Event, next func(context.Context) error) error {
+		methods = append(methods, evt.Method)
+		return next(ctx)
+	}
+	conn := WrapConn(&fakeConnAll{}, WithHooks(hook), WithInterceptors(record))
+
+	ctx := context.Background()
+	rows, err := conn.(driver.QueryerContext).QueryContext(ctx, "SELECT 1", nil)
+	require.NoError(t, err)
+	require.Equal(t, io.EOF, rows.Next(nil))
+	require.NoError(t, rows.Close())
+	stmt, err := conn.(driver.ConnPrepareContext).PrepareContext(ctx, "SELECT 1")
+	require.NoError(t, err)
+	require.NoError(t, stmt.Close())
+	require.NoError(t, conn.(driver.SessionResetter).ResetSession(ctx))
+
+	require.Equal(t, []Method{
+		MethodQuery, MethodRowsNext, MethodRowsClose, MethodPrepare, MethodStmtClose, MethodResetSession,
+	}, methods)
+	require.Len(t, hook.events, 2)
+}
+
+// skipConn leaves every call to the fallback of database/sql.
+type skipConn struct {
+	fakeConn
+}
+
+func (c *skipConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *skipConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return nil, driver.ErrSkip
+}
+
+func TestErrSkip(t *testing.T) {
+	ctx := context.Background()
+	hook := &recordHook{}
+	conn := WrapConn(&skipConn{}, WithHooks(hook))
+
+	_, err := conn.(driver.ExecerContext).ExecContext(ctx, "UPDATE t SET a = 1", nil)
+	require.Equal(t, driver.ErrSkip, err)
+	_, err = conn.(driver.QueryerContext).QueryContext(ctx, "SELECT 1", nil)
+	require.Equal(t, driver.ErrSkip, err)
+
+	stmt, err := conn.(driver.ConnPrepareContext).PrepareContext(ctx, "SELECT 1")
+	require.NoError(t, err)
+	_, err = stmt.Exec(nil) // nolint
+	require.Equal(t, driver.ErrSkip, err)
+	_, err = stmt.Query(nil) // nolint
+	require.Equal(t, driver.ErrSkip, err)
+
+	require.Len(t, hook.events, 1)
+	require.Equal(t, MethodPrepare, hook.events[0].Method)
+}
+
+func TestInterceptResultType(t *testing.T) {
+	hook := &recordHook{}
+	cached := func(ctx context.Context, evt *Event, next func(context.Context) error) error {
+		evt.Result = "cached"
+		return nil
+	}
+	conn := WrapConn(&fakeConnAll{}, WithHooks(hook), WithInterceptors(cached))
+
+	rows, err := conn.(driver.QueryerContext).QueryContext(context.Background(), "SELECT 1", nil)
+	require.Nil(t, rows)
+	require.True(t, errors.Is(err, ErrResultType))
+	require.Len(t, hook.events, 1)
+	require.Equal(t, err, hook.events[0].Err)
+}
+
+func TestOperation(t *testing.T) {
+	hook := &recordHook{}
+	conn := WrapConn(&fakeConn{}, WithHooks(hook))
+
+	_, err := conn.(driver.ExecerContext).ExecContext(context.Background(), "UPDATE `users` SET a = 1", nil)
+	require.NoError(t, err)
+
+	evt := hook.events[0]
+	require.Nil(t, evt.Fingerprint())
+	require.Equal(t, "UPDATE", evt.Operation())
+	require.Equal(t, []string{"users"}, evt.Tables())
+}
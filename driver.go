@@ -5,10 +5,10 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"errors"
-	"fmt"
+	"reflect"
 	"strconv"
+	"strings"
 	"sync"
-	"time"
 )
 
 var regMu sync.Mutex
@@ -21,6 +21,8 @@ var (
 	_ driver.StmtExecContext  = &otStmt{}
 	_ driver.StmtQueryContext = &otStmt{}
 	_ driver.Rows             = &otRows{}
+	_ connAll                 = otConn{}
+	_ rowsAll                 = otRows{}
 )
 
 // Register initializes and registers our otsql wrapped database driver
@@ -63,7 +65,9 @@ func Register(driverName string, options ...Option) (string, error) {
 
 // Wrap takes a SQL driver and wraps it with hook enabled.
 func Wrap(dri driver.Driver, opts ...Option) driver.Driver {
-	return wrapDriver(dri, newOptions(opts))
+	o := newOptions(opts)
+	o.inferSystem(dri)
+	return wrapDriver(dri, o)
 }
 
 type otConnector struct {
@@ -73,33 +77,43 @@ type otConnector struct {
 }
 
 func (oc otConnector) Connect(ctx context.Context) (conn driver.Conn, err error) {
-	evt := newEvent(oc.Options, "", MethodCreateConn, "", nil)
-	before(oc.Hooks, ctx, evt)
+	meta := newConnMeta()
+	evt := newEvent(oc.Options, meta, MethodCreateConn, "", nil)
+	ctx = before(oc.Options, ctx, evt)
 
-	id := fmt.Sprintf("%d", time.Now().UnixNano())
 	defer func() {
 		evt.Err = err
-		evt.Conn = id
-		after(oc.Hooks, ctx, evt)
+		evt.SessionID = meta.sessionID
+		after(oc.Options, ctx, evt)
 	}()
 
-	conn, err = oc.dc.Connect(ctx)
-	if err != nil {
+	if err = intercept(oc.Options, ctx, evt, func(ctx context.Context) error {
+		conn, err := oc.dc.Connect(ctx)
+		evt.Result = conn
+		return err
+	}); err != nil {
 		return nil, err
 	}
 
-	return wrapConn(id, conn, oc.Options), nil
-
+	conn = evt.Result.(driver.Conn)
+	meta.sessionID = oc.sessionID(ctx, conn)
+	return wrapConn(meta, conn, oc.Options), nil
 }
 
 func (oc otConnector) Driver() driver.Driver {
 	return oc.dri
 }
 
+// Unwrap returns the driver.Connector wrapped by otsql.
+func (oc otConnector) Unwrap() driver.Connector {
+	return oc.dc
+}
+
 // WrapConnector allows wrapping a database driver.Connector which eliminates
 // the need to register otsql as an available driver.Driver.
 func WrapConnector(dc driver.Connector, opts ...Option) driver.Connector {
 	o := newOptions(opts)
+	o.inferSystem(dc.Driver())
 	return &otConnector{
 		dc:      dc,
 		dri:     wrapDriver(dc.Driver(), o),
@@ -109,14 +123,27 @@ func WrapConnector(dc driver.Connector, opts ...Option) driver.Connector {
 
 // WrapConn allows an existing driver.Conn to be wrapped by otsql.
 func WrapConn(c driver.Conn, opts ...Option) driver.Conn {
-	return wrapConn(fmt.Sprintf("%d", time.Now().UnixNano()), c, newOptions(opts))
+	o := newOptions(opts)
+	o.inferSystem(c)
+	meta := newConnMeta()
+	meta.sessionID = o.sessionID(context.Background(), c)
+	return wrapConn(meta, c, o)
+}
+
+// driverUnwrapper exposes otDriver.Unwrap when otDriver is hidden in struct.
+type driverUnwrapper interface {
+	Unwrap() driver.Driver
 }
 
 func wrapDriver(dri driver.Driver, o *Options) driver.Driver {
+	d := otDriver{Driver: dri, Options: o}
 	if _, ok := dri.(driver.DriverContext); ok {
-		return otDriver{Driver: dri, Options: o}
+		return d
 	}
-	return struct{ driver.Driver }{otDriver{Driver: dri, Options: o}}
+	return struct {
+		driver.Driver
+		driverUnwrapper
+	}{d, d}
 }
 
 type otDriver struct {
@@ -125,25 +152,36 @@ type otDriver struct {
 	*Options
 }
 
+// Unwrap returns the driver.Driver wrapped by otsql.
+func (d otDriver) Unwrap() driver.Driver {
+	return d.Driver
+}
+
 func (d otDriver) Open(name string) (conn driver.Conn, err error) {
 	ctx := context.Background()
+	o := addInstance(d.Options, name)
 
-	evt := newEvent(d.Options, "", MethodCreateConn, "", nil)
-	before(d.Hooks, ctx, evt)
+	meta := newConnMeta()
+	evt := newEvent(o, meta, MethodCreateConn, "", nil)
+	ctx = before(o, ctx, evt)
 
-	id := fmt.Sprintf("%d", time.Now().UnixNano())
 	defer func() {
 		evt.Err = err
-		evt.Conn = id
-		after(d.Hooks, ctx, evt)
+		evt.SessionID = meta.sessionID
+		after(o, ctx, evt)
 	}()
 
-	conn, err = d.Driver.Open(name)
-	if err != nil {
+	if err = intercept(o, ctx, evt, func(context.Context) error {
+		conn, err := d.Driver.Open(name)
+		evt.Result = conn
+		return err
+	}); err != nil {
 		return nil, err
 	}
 
-	return wrapConn(id, conn, addInstance(d.Options, name)), nil
+	conn = evt.Result.(driver.Conn)
+	meta.sessionID = o.sessionID(ctx, conn)
+	return wrapConn(meta, conn, o), nil
 }
 
 func (d otDriver) OpenConnector(name string) (driver.Connector, error) {
@@ -158,13 +196,57 @@ func (d otDriver) OpenConnector(name string) (driver.Connector, error) {
 	}, nil
 }
 
+// addInstance returns a copy of o with instance and database parsed from dsn,
+// o is shared by every connection of the driver and must not be modified.
 func addInstance(o *Options, dsn string) *Options {
-	instance, database := parseDSN(dsn)
-	if o.Instance == "" && instance != "" {
-		o.Instance = instance
+	ci, err := ParseDSN(dsn)
+	if err != nil {
+		return o
 	}
-	if database != "" {
-		o.Database = database
+
+	copied := *o
+	copied.ConnInfo = ci
+	if copied.System == "" {
+		copied.System = ci.System
+	}
+	if instance := ci.Instance(); copied.Instance == "" && instance != "" {
+		copied.Instance = instance
+	}
+	if ci.Database != "" {
+		copied.Database = ci.Database
+	}
+	return &copied
+}
+
+// driverSystems maps package path prefixes of drivers to database systems.
+var driverSystems = []struct {
+	pkg    string
+	system string
+}{
+	{"github.com/go-sql-driver/mysql", SystemMySQL},
+	{"github.com/lib/pq", SystemPostgreSQL},
+	{"github.com/jackc/pgx", SystemPostgreSQL},
+	{"github.com/denisenkom/go-mssqldb", SystemMSSQL},
+	{"github.com/microsoft/go-mssqldb", SystemMSSQL},
+	{"github.com/ClickHouse/clickhouse-go", SystemClickHouse},
+	{"github.com/mattn/go-sqlite3", SystemSQLite},
+	{"modernc.org/sqlite", SystemSQLite},
+}
+
+// inferSystem sets System from the package of v, a driver.Driver or driver.Conn,
+// unless it has been set.
+func (o *Options) inferSystem(v interface{}) {
+	if o.System != "" || v == nil {
+		return
+	}
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for _, ds := range driverSystems {
+		if strings.HasPrefix(t.PkgPath(), ds.pkg) {
+			o.System = ds.system
+			return
+		}
 	}
-	return o
 }
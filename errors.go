@@ -1,12 +1,163 @@
 package otsql
 
 import (
-	"google.golang.org/grpc/codes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strconv"
 )
 
-func ErrToCode(err error) codes.Code {
-	if err == nil {
-		return codes.OK
+// ErrorClassifier maps the error of a driver call to a code.
+type ErrorClassifier interface {
+	Classify(err error) Code
+}
+
+// ErrorClassifierFunc is an adapter to allow the use of ordinary functions as ErrorClassifier.
+type ErrorClassifierFunc func(err error) Code
+
+func (f ErrorClassifierFunc) Classify(err error) Code {
+	return f(err)
+}
+
+// DefaultErrorClassifier recognizes errors of context, database/sql and
+// database/sql/driver, Postgres errors which have a SQLState method, like pq and pgx,
+// and go-sql-driver/mysql's MySQLError.
+var DefaultErrorClassifier ErrorClassifier = ErrorClassifierFunc(classify)
+
+// ErrToCode classify err with DefaultErrorClassifier.
+func ErrToCode(err error) Code {
+	return DefaultErrorClassifier.Classify(err)
+}
+
+func classify(err error) Code {
+	switch {
+	case err == nil:
+		return CodeOK
+	case errors.Is(err, context.Canceled):
+		return CodeCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeDeadlineExceeded
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone):
+		return CodeUnavailable
+	case errors.Is(err, driver.ErrSkip):
+		return CodeUnimplemented
+	case errors.Is(err, sql.ErrNoRows):
+		return CodeNotFound
+	case errors.Is(err, sql.ErrTxDone):
+		return CodeFailedPrecondition
+	}
+
+	var pgErr interface{ SQLState() string }
+	if errors.As(err, &pgErr) {
+		return SQLStateToCode(pgErr.SQLState())
+	}
+
+	if number, ok := mysqlErrorNumber(err); ok {
+		return MySQLErrorToCode(number)
+	}
+
+	return CodeUnknown
+}
+
+// ErrorStatusCode returns the status code of err reported by the database,
+// such as the SQLSTATE of Postgres or the error number of MySQL,
+// empty if err carries none.
+func ErrorStatusCode(err error) string {
+	var pgErr interface{ SQLState() string }
+	if errors.As(err, &pgErr) {
+		return pgErr.SQLState()
+	}
+	if number, ok := mysqlErrorNumber(err); ok {
+		return strconv.Itoa(int(number))
+	}
+	return ""
+}
+
+// mysqlErrorNumber extracts Number from go-sql-driver/mysql's MySQLError,
+// without depending on the driver.
+func mysqlErrorNumber(err error) (uint16, bool) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		v := reflect.Indirect(reflect.ValueOf(err))
+		if v.Kind() != reflect.Struct || v.Type().Name() != "MySQLError" {
+			continue
+		}
+		if f := v.FieldByName("Number"); f.IsValid() && f.Kind() == reflect.Uint16 {
+			return uint16(f.Uint()), true
+		}
+	}
+	return 0, false
+}
+
+// SQLStateToCode maps a SQLSTATE, as used by Postgres, to code.
+// See https://www.postgresql.org/docs/current/errcodes-appendix.html
+func SQLStateToCode(state string) Code {
+	switch state {
+	case "23505": // unique_violation
+		return CodeAlreadyExists
+	case "42501": // insufficient_privilege
+		return CodePermissionDenied
+	case "42P01", "42703", "3D000": // undefined_table, undefined_column, invalid_catalog_name
+		return CodeNotFound
+	case "57014": // query_canceled
+		return CodeCanceled
+	}
+
+	if len(state) < 2 {
+		return CodeUnknown
+	}
+	switch state[:2] {
+	case "00":
+		return CodeOK
+	case "08": // connection exception
+		return CodeUnavailable
+	case "0A": // feature not supported
+		return CodeUnimplemented
+	case "22", "42": // data exception, syntax error or access rule violation
+		return CodeInvalidArgument
+	case "23", "25": // integrity constraint violation, invalid transaction state
+		return CodeFailedPrecondition
+	case "28": // invalid authorization specification
+		return CodeUnauthenticated
+	case "40": // transaction rollback, including deadlock_detected and serialization_failure
+		return CodeAborted
+	case "53": // insufficient resources
+		return CodeResourceExhausted
+	case "57": // operator intervention
+		return CodeUnavailable
+	case "XX": // internal error
+		return CodeInternal
+	}
+	return CodeUnknown
+}
+
+// MySQLErrorToCode maps a MySQL server error number to code.
+// See https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html
+func MySQLErrorToCode(number uint16) Code {
+	switch number {
+	case 1007, 1050, 1062, 1586: // ER_DB_CREATE_EXISTS, ER_TABLE_EXISTS_ERROR, ER_DUP_ENTRY, ER_DUP_ENTRY_WITH_KEY_NAME
+		return CodeAlreadyExists
+	case 1205, 1213: // ER_LOCK_WAIT_TIMEOUT, ER_LOCK_DEADLOCK
+		return CodeAborted
+	case 1044, 1142, 1143, 1227: // ER_DBACCESS_DENIED_ERROR, ER_TABLEACCESS_DENIED_ERROR, ER_COLUMNACCESS_DENIED_ERROR, ER_SPECIFIC_ACCESS_DENIED_ERROR
+		return CodePermissionDenied
+	case 1045: // ER_ACCESS_DENIED_ERROR
+		return CodeUnauthenticated
+	case 1049, 1054, 1146: // ER_BAD_DB_ERROR, ER_BAD_FIELD_ERROR, ER_NO_SUCH_TABLE
+		return CodeNotFound
+	case 1048, 1064, 1366, 1406: // ER_BAD_NULL_ERROR, ER_PARSE_ERROR, ER_TRUNCATED_WRONG_VALUE_FOR_FIELD, ER_DATA_TOO_LONG
+		return CodeInvalidArgument
+	case 1451, 1452: // ER_ROW_IS_REFERENCED_2, ER_NO_REFERENCED_ROW_2
+		return CodeFailedPrecondition
+	case 1040, 1041: // ER_CON_COUNT_ERROR, ER_OUT_OF_RESOURCES
+		return CodeResourceExhausted
+	case 1317: // ER_QUERY_INTERRUPTED
+		return CodeCanceled
+	case 3024: // ER_QUERY_TIMEOUT
+		return CodeDeadlineExceeded
+	case 1053, 2006, 2013: // ER_SERVER_SHUTDOWN, CR_SERVER_GONE_ERROR, CR_SERVER_LOST
+		return CodeUnavailable
 	}
-	return codes.Unknown
+	return CodeUnknown
 }
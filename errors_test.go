@@ -0,0 +1,58 @@
+package otsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type MySQLError struct {
+	Number  uint16
+	Message string
+}
+
+func (e *MySQLError) Error() string { return e.Message }
+
+type pgError struct {
+	Code string
+}
+
+func (e *pgError) Error() string { return e.Code }
+
+func (e *pgError) SQLState() string { return e.Code }
+
+func TestErrToCode(t *testing.T) {
+	fixtures := []struct {
+		err  error
+		code Code
+	}{
+		{nil, CodeOK},
+		{errors.New("unknown"), CodeUnknown},
+		{context.Canceled, CodeCanceled},
+		{fmt.Errorf("query: %w", context.DeadlineExceeded), CodeDeadlineExceeded},
+		{driver.ErrBadConn, CodeUnavailable},
+		{sql.ErrNoRows, CodeNotFound},
+		{&MySQLError{Number: 1062}, CodeAlreadyExists},
+		{fmt.Errorf("exec: %w", &MySQLError{Number: 1213}), CodeAborted},
+		{&MySQLError{Number: 1142}, CodePermissionDenied},
+		{&pgError{Code: "23505"}, CodeAlreadyExists},
+		{&pgError{Code: "40P01"}, CodeAborted},
+		{&pgError{Code: "42501"}, CodePermissionDenied},
+		{&pgError{Code: "42601"}, CodeInvalidArgument},
+	}
+
+	for _, f := range fixtures {
+		require.Equal(t, f.code, ErrToCode(f.err), "%v", f.err)
+	}
+}
+
+func TestErrorStatusCode(t *testing.T) {
+	require.Equal(t, "1062", ErrorStatusCode(fmt.Errorf("exec: %w", &MySQLError{Number: 1062})))
+	require.Equal(t, "40P01", ErrorStatusCode(&pgError{Code: "40P01"}))
+	require.Equal(t, "", ErrorStatusCode(context.Canceled))
+}
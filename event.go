@@ -2,7 +2,13 @@ package otsql
 
 import (
 	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
 	"time"
+
+	"github.com/j2gg0s/otsql/fingerprint"
 )
 
 type Hook interface {
@@ -10,17 +16,100 @@ type Hook interface {
 	After(context.Context, *Event)
 }
 
-func before(hooks []Hook, ctx context.Context, evt *Event) context.Context {
-	for _, hook := range hooks {
-		ctx = hook.Before(ctx, evt)
+func before(o *Options, ctx context.Context, evt *Event) context.Context {
+	evt.Tags = TagsFromContext(ctx)
+	if evt.Query != "" && evt.info == nil {
+		evt.info = &queryInfo{query: evt.Query, fingerprintB: o.FingerprintB}
+	}
+	co := CallOptionsFromContext(ctx)
+	for _, hook := range o.Hooks {
+		if co.HookEnabled(hook) {
+			ctx = hook.Before(ctx, evt)
+		}
 	}
 	return ctx
 }
 
-func after(hooks []Hook, ctx context.Context, evt *Event) {
-	for _, hook := range hooks {
-		hook.After(ctx, evt)
+func after(o *Options, ctx context.Context, evt *Event) {
+	if evt.EndAt.IsZero() {
+		// interceptors short-circuited the driver call
+		evt.EndAt = o.now()
+	}
+	evt.Duration = evt.EndAt.Sub(evt.BeginAt)
+	evt.Code = o.classify(evt.Err)
+	co := CallOptionsFromContext(ctx)
+	for _, hook := range o.Hooks {
+		if co.HookEnabled(hook) {
+			hook.After(ctx, evt)
+		}
+	}
+}
+
+// skipped reports whether the driver call returned driver.ErrSkip,
+// database/sql then retries it in another way which has its own event.
+// The event of a skipped call is dropped without calling Hook.After,
+// so a span started by Hook.Before is never ended and never exported.
+func skipped(err error) bool {
+	return errors.Is(err, driver.ErrSkip)
+}
+
+// Interceptor wraps the driver call described by evt.
+// Calling next runs the remaining interceptors and then the driver call itself,
+// so an interceptor can retry by calling next again, short-circuit by not
+// calling it at all, or swap what the wrapper returns by replacing evt.Result.
+// An interceptor which does not call next but returns nil must set evt.Result
+// to a value of the same type the driver call would have produced,
+// otherwise the call fails with ErrResultType.
+// Interceptors wrap every driver call, even those not reported to hooks,
+// such as RowsNext unless Options.RowsNextB is set.
+type Interceptor func(ctx context.Context, evt *Event, next func(context.Context) error) error
+
+// ErrResultType is returned by a call whose interceptors returned nil
+// but left Event.Result of a type the wrapper can not return.
+var ErrResultType = errors.New("otsql: unexpected type of Event.Result")
+
+func intercept(o *Options, ctx context.Context, evt *Event, call func(context.Context) error) error {
+	next := func(ctx context.Context) error {
+		err := call(ctx)
+		evt.EndAt = o.now()
+		return err
+	}
+	for i := len(o.Interceptors) - 1; i >= 0; i-- {
+		interceptor, n := o.Interceptors[i], next
+		next = func(ctx context.Context) error {
+			return interceptor(ctx, evt, n)
+		}
+	}
+	if err := next(ctx); err != nil {
+		return err
+	}
+	return checkResult(evt)
+}
+
+// checkResult returns ErrResultType if evt.Result is not what the wrapper of
+// evt.Method returns, nil for methods without result.
+func checkResult(evt *Event) error {
+	var ok bool
+	switch evt.Method {
+	case MethodExec:
+		_, ok = evt.Result.(driver.Result)
+	case MethodQuery:
+		_, ok = evt.Result.(driver.Rows)
+	case MethodPrepare:
+		_, ok = evt.Result.(driver.Stmt)
+	case MethodBegin:
+		_, ok = evt.Result.(driver.Tx)
+	case MethodCreateConn:
+		_, ok = evt.Result.(driver.Conn)
+	case MethodLastInsertId, MethodRowsAffected:
+		_, ok = evt.Result.(int64)
+	default:
+		return nil
 	}
+	if ok {
+		return nil
+	}
+	return fmt.Errorf("%w: %T of %s", ErrResultType, evt.Result, evt.Method)
 }
 
 type Method string
@@ -40,6 +129,10 @@ var (
 	MethodRowsClose    Method = "rows_close"
 	MethodRowsNext     Method = "rows_next"
 
+	MethodRowsNextResultSet Method = "rows_next_result_set"
+
+	MethodStmtClose Method = "stmt_close"
+
 	MethodCreateConn   Method = "create_conn"
 	MethodCloseConn    Method = "close_conn"
 	MethodResetSession Method = "reset_session"
@@ -48,28 +141,222 @@ var (
 type Event struct {
 	Instance string
 	Database string
+	// System is the database system, such as SystemMySQL, empty if unknown.
+	System string
+	// ConnInfo is parsed from dsn and shared by events, should not be modified.
+	ConnInfo *ConnInfo
+
+	Method Method
+	// Query and Args are passed to the driver after Before hooks have run,
+	// so hooks and interceptors can rewrite them.
+	// Args is []driver.NamedValue or []driver.Value, depending on the method,
+	// and should be replaced with a value of the same type.
+	// Statements executed through a prepared driver.Stmt only honour Args,
+	// the query has already been sent to the server by Prepare.
+	Query string
+	Args  interface{}
+
+	// BeginAt is when the event is created, before Before hooks run.
+	// EndAt is when the driver call returned, the last one if retried by
+	// an interceptor, or when rows are done for query events finished by rows,
+	// and Duration is the time between them.
+	// Both are set before After hooks run, hooks should use them
+	// instead of measuring on their own.
+	BeginAt  time.Time
+	EndAt    time.Time
+	Duration time.Duration
+
+	// info is derived from Query on first use, see Event.Fingerprint
+	// and Event.Operation.
+	info *queryInfo
+
+	// Prepared is true for calls made through a prepared driver.Stmt.
+	Prepared bool
+	// StmtID is the id of prepared statement, unique in process,
+	// set for MethodPrepare and calls made through the statement.
+	StmtID string
+	// StmtExecutions counts calls made through the statement, including this one.
+	StmtExecutions int64
+	// Prepare is a copy of the event of Prepare which created the statement,
+	// set for calls made through the statement. It keeps only Query, StmtID,
+	// Conn, BeginAt and values set by hooks, see Event.SetValue, so statements
+	// cached by database/sql do not retain request-scoped data, such as Tags.
+	Prepare *Event
+
+	// RowsAt is when the driver returned rows, set only for query events
+	// finished by their rows, see Options.DeferQueryEndB. The query is
+	// executed from BeginAt to RowsAt and fetched from RowsAt to EndAt.
+	RowsAt time.Time
 
-	Method  Method
-	Query   string
-	Args    interface{}
-	BeginAt time.Time
+	// RowsReturned, FirstRowLatency and FetchDuration summarize the fetching
+	// of rows, set only for MethodRowsClose and query events finished by rows.
+	// Both durations are measured from when the driver returned the rows,
+	// FetchDuration ends when Next returned io.EOF, an error, or rows is closed.
+	RowsReturned    int64
+	FirstRowLatency time.Duration
+	FetchDuration   time.Duration
+
+	// RowsAffected and LastInsertID are read from the result of a successful exec,
+	// set only if Options.ExecResultB is set and the driver supports them.
+	RowsAffected *int64
+	LastInsertID *int64
 
 	Err error
+	// Code is Err classified by Options.ErrorClassifier,
+	// set before After hooks run.
+	Code Code
+
+	// Result is what the driver call returned, such as driver.Result,
+	// driver.Rows, driver.Stmt, driver.Tx or driver.Conn.
+	// It is set when the driver call returns and may be replaced by an Interceptor.
+	Result interface{}
 
 	CloseFuncs []func(context.Context, error)
 
+	// Conn is the id of connection, unique in process and increases monotonically.
 	Conn string
+	// SessionID is the server-side id of connection, such as MySQL's CONNECTION_ID(),
+	// set only if Options.SessionID is configured.
+	SessionID string
+
+	// Tags are attached to the context of call by WithTags, should not be modified.
+	Tags map[string]string
+
+	// TxID is the id of transaction in progress on the connection,
+	// set from begin until commit or rollback.
+	TxID string
+	// TxOptions is options of the transaction in progress, should not be modified.
+	TxOptions *driver.TxOptions
+
+	// values are set by hooks, see Event.SetValue.
+	values *eventValues
+}
+
+type eventValues struct {
+	mu sync.Mutex
+	m  map[interface{}]interface{}
 }
 
-func newEvent(o *Options, conn string, method Method, query string, args interface{}) *Event {
+// SetValue associates value with key on evt, key should be of an unexported
+// type like keys of context.Context. Hooks use it to pass what later events
+// need, such as the span of Prepare to calls of the statement, see Event.Prepare.
+func (evt *Event) SetValue(key, value interface{}) {
+	if evt.values == nil {
+		evt.values = &eventValues{}
+	}
+	evt.values.mu.Lock()
+	defer evt.values.mu.Unlock()
+	if evt.values.m == nil {
+		evt.values.m = make(map[interface{}]interface{})
+	}
+	evt.values.m[key] = value
+}
+
+// Value returns the value associated with key by SetValue, nil if absent.
+func (evt *Event) Value(key interface{}) interface{} {
+	if evt.values == nil {
+		return nil
+	}
+	evt.values.mu.Lock()
+	defer evt.values.mu.Unlock()
+	return evt.values.m[key]
+}
+
+// prepared returns the copy of evt of Prepare kept by the statement,
+// see Event.Prepare. Values set by hooks later are shared with the copy.
+func (evt *Event) prepared() *Event {
+	if evt.values == nil {
+		evt.values = &eventValues{}
+	}
 	return &Event{
+		Method:  evt.Method,
+		Query:   evt.Query,
+		StmtID:  evt.StmtID,
+		Conn:    evt.Conn,
+		BeginAt: evt.BeginAt,
+		values:  evt.values,
+	}
+}
+
+// Fingerprint returns the normalized Query and its digest, which is bounded
+// enough to group queries by, nil unless Options.FingerprintB is set.
+// It is computed from Query as passed to Before hooks on the first call,
+// only once for each prepared statement.
+func (evt *Event) Fingerprint() *fingerprint.Fingerprint {
+	if evt.info == nil || !evt.info.fingerprintB {
+		return nil
+	}
+	return evt.info.fingerprint()
+}
+
+// Operation returns the operation parsed from Query, such as SELECT,
+// see fingerprint.Parse. Like Fingerprint, it is parsed on the first call,
+// but does not require Options.FingerprintB.
+func (evt *Event) Operation() string {
+	if evt.info == nil {
+		return ""
+	}
+	operation, _ := evt.info.parse()
+	return operation
+}
+
+// Tables returns the tables parsed from Query, such as [users],
+// see Event.Operation. It should not be modified.
+func (evt *Event) Tables() []string {
+	if evt.info == nil {
+		return nil
+	}
+	_, tables := evt.info.parse()
+	return tables
+}
+
+// queryInfo derives from query on first use, it is shared by events of
+// a prepared statement, so the work is done once for each statement.
+type queryInfo struct {
+	query        string
+	fingerprintB bool
+
+	fpOnce sync.Once
+	fp     *fingerprint.Fingerprint
+
+	parseOnce sync.Once
+	operation string
+	tables    []string
+}
+
+func (q *queryInfo) fingerprint() *fingerprint.Fingerprint {
+	q.fpOnce.Do(func() {
+		q.fp = fingerprint.New(q.query)
+	})
+	return q.fp
+}
+
+func (q *queryInfo) parse() (string, []string) {
+	q.parseOnce.Do(func() {
+		q.operation, q.tables = fingerprint.Parse(q.query)
+	})
+	return q.operation, q.tables
+}
+
+func newEvent(o *Options, meta *connMeta, method Method, query string, args interface{}) *Event {
+	evt := &Event{
 		Instance: o.Instance,
 		Database: o.Database,
+		System:   o.System,
+		ConnInfo: o.ConnInfo,
 
-		Conn:    conn,
 		Method:  method,
 		Query:   query,
 		Args:    args,
-		BeginAt: time.Now(),
+		BeginAt: o.now(),
+	}
+	if meta != nil {
+		evt.Conn = meta.id
+		evt.SessionID = meta.sessionID
+		if tx := meta.currentTx(); tx != nil {
+			evt.TxID = tx.id
+			evt.TxOptions = &tx.opts
+		}
 	}
+	return evt
 }
@@ -0,0 +1,61 @@
+package otsql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntercept(t *testing.T) {
+	var calls []string
+	record := func(name string) Interceptor {
+		return func(ctx context.Context, evt *Event, next func(context.Context) error) error {
+			calls = append(calls, name+".before")
+			err := next(ctx)
+			calls = append(calls, name+".after")
+			return err
+		}
+	}
+
+	evt := &Event{}
+	err := intercept(&Options{Interceptors: []Interceptor{record("a"), record("b")}}, context.Background(), evt, func(context.Context) error {
+		calls = append(calls, "call")
+		evt.Result = "origin"
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a.before", "b.before", "call", "b.after", "a.after"}, calls)
+	require.Equal(t, "origin", evt.Result)
+
+	errRetry := errors.New("retry")
+	attempts := 0
+	retry := func(ctx context.Context, evt *Event, next func(context.Context) error) error {
+		err := next(ctx)
+		for i := 0; i < 2 && errors.Is(err, errRetry); i++ {
+			err = next(ctx)
+		}
+		return err
+	}
+	err = intercept(&Options{Interceptors: []Interceptor{retry}}, context.Background(), evt, func(context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errRetry
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, attempts)
+
+	cached := func(ctx context.Context, evt *Event, next func(context.Context) error) error {
+		evt.Result = "cached"
+		return nil
+	}
+	err = intercept(&Options{Interceptors: []Interceptor{cached}}, context.Background(), evt, func(context.Context) error {
+		t.Fatal("short-circuited call should not run")
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "cached", evt.Result)
+}
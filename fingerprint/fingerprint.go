@@ -0,0 +1,295 @@
+// Package fingerprint normalizes sql queries, so queries which differ only in
+// literals, placeholders, the length of IN-lists, whitespace or comments share
+// the same fingerprint, which is bounded enough to be a label of metrics.
+// It also parses the operation and tables of queries, which is independent of
+// normalizing, see Parse.
+package fingerprint
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// Fingerprint of a query.
+type Fingerprint struct {
+	// Query is the normalized query, see Normalize.
+	Query string
+	// Digest is the stable hash of Query, 16 hex characters.
+	Digest string
+}
+
+// New normalizes and hashes query.
+func New(query string) *Fingerprint {
+	normalized := Normalize(query)
+	return &Fingerprint{
+		Query:  normalized,
+		Digest: Digest(normalized),
+	}
+}
+
+// Digest returns the 64-bit FNV-1a hash of normalized in hex.
+func Digest(normalized string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(normalized))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+const (
+	placeholder = "?"
+	list        = "?+"
+)
+
+// Normalize returns the normalized form of query:
+//   - comments are stripped and whitespace is collapsed,
+//   - keywords and unquoted identifiers are lower-cased,
+//   - string and numeric literals and placeholders, such as ?, $1, :name and @p1,
+//     are replaced by ?, string literals include dollar-quoted $tag$...$tag$,
+//     and backslash escapes only in escape strings, E'...',
+//   - lists of only placeholders, such as IN (1, 2, 3), are collapsed to (?+),
+//     and so do repeated rows of VALUES.
+//
+// For example, "SELECT * FROM t WHERE id IN (1, 2) AND name = 'a' -- find"
+// is normalized to "select * from t where id in (?+) and name = ?".
+func Normalize(query string) string {
+	return join(collapse(tokenize(query)))
+}
+
+// HasComment reports whether query has a comment, -- or /* */, outside of
+// quoted strings and identifiers.
+func HasComment(query string) bool {
+	for i := 0; i < len(query); {
+		switch c := query[i]; {
+		case c == '-' && strings.HasPrefix(query[i:], "--"),
+			c == '/' && strings.HasPrefix(query[i:], "/*"):
+			return true
+		case c == '\'':
+			i = skipQuoted(query, i, c, isEString(query, i))
+		case c == '"' || c == '`':
+			i = skipQuoted(query, i, c, false)
+		case c == '$' && (i == 0 || !isWordChar(query[i-1])) && dollarTag(query, i) != "":
+			i = skipDollarQuoted(query, i)
+		default:
+			i++
+		}
+	}
+	return false
+}
+
+func tokenize(query string) []string {
+	var tokens []string
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case isSpace(c):
+			i++
+		case c == '-' && strings.HasPrefix(query[i:], "--"), c == '#':
+			i = skipLine(query, i)
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				i = len(query)
+			} else {
+				i += end + 4
+			}
+		case c == '\'':
+			i = skipQuoted(query, i, '\'', false)
+			tokens = append(tokens, placeholder)
+		case (c == 'E' || c == 'e') && i+1 < len(query) && query[i+1] == '\'':
+			i = skipQuoted(query, i+1, '\'', true)
+			tokens = append(tokens, placeholder)
+		case c == '"' || c == '`':
+			end := skipQuoted(query, i, c, false)
+			tokens = append(tokens, query[i:end])
+			i = end
+		case isDigit(c) || (c == '.' && i+1 < len(query) && isDigit(query[i+1])):
+			i = skipNumber(query, i)
+			tokens = append(tokens, placeholder)
+		case c == '?':
+			i++
+			tokens = append(tokens, placeholder)
+		case c == '$' && dollarTag(query, i) != "":
+			i = skipDollarQuoted(query, i)
+			tokens = append(tokens, placeholder)
+		case c == '$' && i+1 < len(query) && isDigit(query[i+1]):
+			i = skipWord(query, i+1)
+			tokens = append(tokens, placeholder)
+		case (c == ':' || c == '@') && i+1 < len(query) && isLetter(query[i+1]) &&
+			(i == 0 || query[i-1] != c):
+			i = skipWord(query, i+1)
+			tokens = append(tokens, placeholder)
+		case isLetter(c):
+			end := skipWord(query, i)
+			tokens = append(tokens, strings.ToLower(query[i:end]))
+			i = end
+		case c == '(' || c == ')' || c == ',' || c == '.' || c == ';':
+			i++
+			tokens = append(tokens, string(c))
+		default:
+			end := i + 1
+			for end < len(query) && isOperator(query[end]) &&
+				!strings.HasPrefix(query[end:], "--") && !strings.HasPrefix(query[end:], "/*") {
+				end++
+			}
+			tokens = append(tokens, query[i:end])
+			i = end
+		}
+	}
+
+	// a trailing semicolon terminates the statement only.
+	for len(tokens) > 0 && tokens[len(tokens)-1] == ";" {
+		tokens = tokens[:len(tokens)-1]
+	}
+	return tokens
+}
+
+// collapse replaces lists of only placeholders by (?+),
+// and repeated (?+) separated by comma by a single one.
+func collapse(tokens []string) []string {
+	out := make([]string, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		end := placeholderList(tokens, i)
+		if end < 0 {
+			out = append(out, tokens[i])
+			continue
+		}
+		i = end
+		if n := len(out); n >= 4 &&
+			out[n-4] == "(" && out[n-3] == list && out[n-2] == ")" && out[n-1] == "," {
+			out = out[:n-1]
+			continue
+		}
+		out = append(out, "(", list, ")")
+	}
+	return out
+}
+
+// placeholderList returns the index of the closing parenthesis if tokens[i:]
+// starts with a list of only placeholders, -1 if not.
+func placeholderList(tokens []string, i int) int {
+	if tokens[i] != "(" {
+		return -1
+	}
+	for j := i + 1; j+1 < len(tokens); j += 2 {
+		if tokens[j] != placeholder && tokens[j] != list {
+			return -1
+		}
+		switch tokens[j+1] {
+		case ")":
+			return j + 1
+		case ",":
+		default:
+			return -1
+		}
+	}
+	return -1
+}
+
+func join(tokens []string) string {
+	var b strings.Builder
+	for i, token := range tokens {
+		if i > 0 {
+			prev := tokens[i-1]
+			if prev != "(" && prev != "." && token != ")" && token != "," && token != "." {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(token)
+	}
+	return b.String()
+}
+
+func skipLine(query string, i int) int {
+	end := strings.IndexByte(query[i:], '\n')
+	if end < 0 {
+		return len(query)
+	}
+	return i + end + 1
+}
+
+// skipQuoted returns the index after the quoted string starting at i,
+// doubled quotes are part of the string, so are backslash escapes if escape.
+func skipQuoted(query string, i int, quote byte, escape bool) int {
+	for j := i + 1; j < len(query); j++ {
+		switch query[j] {
+		case '\\':
+			if escape {
+				j++
+			}
+		case quote:
+			if j+1 < len(query) && query[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(query)
+}
+
+// isEString reports whether the quote at i starts an escape string, E'...'.
+func isEString(query string, i int) bool {
+	return i > 0 && (query[i-1] == 'E' || query[i-1] == 'e') && (i == 1 || !isWordChar(query[i-2]))
+}
+
+// dollarTag returns the tag, such as $$ or $tag$, if a dollar-quoted string
+// starts at i, empty if not.
+func dollarTag(query string, i int) string {
+	j := i + 1
+	if j < len(query) && isLetter(query[j]) {
+		for j < len(query) && (isLetter(query[j]) || isDigit(query[j])) {
+			j++
+		}
+	}
+	if j < len(query) && query[j] == '$' {
+		return query[i : j+1]
+	}
+	return ""
+}
+
+// skipDollarQuoted returns the index after the dollar-quoted string starting at i.
+func skipDollarQuoted(query string, i int) int {
+	tag := dollarTag(query, i)
+	end := strings.Index(query[i+len(tag):], tag)
+	if end < 0 {
+		return len(query)
+	}
+	return i + len(tag) + end + len(tag)
+}
+
+func skipNumber(query string, i int) int {
+	for i < len(query) {
+		c := query[i]
+		switch {
+		case isDigit(c) || isLetter(c) || c == '.':
+		case (c == '+' || c == '-') && (query[i-1] == 'e' || query[i-1] == 'E'):
+		default:
+			return i
+		}
+		i++
+	}
+	return i
+}
+
+func skipWord(query string, i int) int {
+	for i < len(query) && (isLetter(query[i]) || isDigit(query[i]) || query[i] == '$') {
+		i++
+	}
+	return i
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_' || c >= 0x80
+}
+
+func isWordChar(c byte) bool { return isLetter(c) || isDigit(c) || c == '$' }
+
+func isOperator(c byte) bool {
+	return strings.IndexByte("<>=!~+-*/%&|^:", c) >= 0
+}
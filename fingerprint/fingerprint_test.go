@@ -0,0 +1,69 @@
+package fingerprint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	for _, c := range []struct {
+		query string
+		want  string
+	}{
+		{
+			"SELECT * FROM t WHERE id IN (1, 2) AND name = 'a' -- find",
+			"select * from t where id in (?+) and name = ?",
+		},
+		{
+			"select *\n  from t\twhere id in (?,?,?) /* comment */ and name = 'it''s';",
+			"select * from t where id in (?+) and name = ?",
+		},
+		{
+			"INSERT INTO `t` (a, b) VALUES (1, 'x'), (2, 'y'), (3, 'z')",
+			"insert into `t` (a, b) values (?+)",
+		},
+		{
+			`SELECT "User".id FROM "User" WHERE age > $1 AND score <= 1.5e-3`,
+			`select "User".id from "User" where age > ? and score <= ?`,
+		},
+		{
+			"UPDATE t SET a = :a, b = @p1 WHERE c::text = 'x' # mysql comment",
+			"update t set a = ?, b = ? where c :: text = ?",
+		},
+		{
+			"SELECT count(*) FROM t2 WHERE x IN (SELECT y FROM t3 WHERE z = 0x1F)",
+			"select count (*) from t2 where x in (select y from t3 where z = ?)",
+		},
+		{
+			"SELECT $$a;b$$ FROM t WHERE a = $tag$it's -- $$ fine$tag$ AND b = $1",
+			"select ? from t where a = ? and b = ?",
+		},
+		{
+			`SELECT 'C:\' FROM t WHERE a = E'it\'s' AND b = e'\\'`,
+			"select ? from t where a = ? and b = ?",
+		},
+	} {
+		require.Equal(t, c.want, Normalize(c.query), c.query)
+	}
+}
+
+func TestDigest(t *testing.T) {
+	a := New("SELECT * FROM t WHERE id = 1")
+	b := New("select *   from t where id = ?")
+	require.Equal(t, a, b)
+	require.Len(t, a.Digest, 16)
+	require.NotEqual(t, a.Digest, New("SELECT * FROM t WHERE name = 1").Digest)
+}
+
+func TestHasComment(t *testing.T) {
+	require.True(t, HasComment("SELECT 1 -- c"))
+	require.True(t, HasComment("SELECT /* c */ 1"))
+	require.False(t, HasComment("SELECT * FROM t WHERE note = 'a--b'"))
+	require.False(t, HasComment("SELECT * FROM t WHERE note LIKE '%/*%' AND \"a--b\" = 1"))
+	require.False(t, HasComment("SELECT 'it''s -- fine'"))
+	require.False(t, HasComment("SELECT $$a -- b$$, $fn$ /* $fn$"))
+	require.True(t, HasComment("SELECT a$$ -- c"))
+	require.False(t, HasComment(`SELECT E'it\'s -- fine'`))
+	require.True(t, HasComment(`SELECT 'C:\' -- c`))
+}
@@ -0,0 +1,263 @@
+package fingerprint
+
+import "strings"
+
+// Parse returns the operation and the tables of query.
+// The operation is the leading keyword of the first statement in upper case,
+// such as SELECT, INSERT, UPDATE, DELETE, CREATE or CALL, common table
+// expressions are skipped, so WITH ... SELECT is a SELECT.
+// The tables are read after FROM, JOIN, INTO, UPDATE and TABLE of all
+// statements, in order of appearance and without quotes, names of common
+// table expressions and subqueries are excluded.
+//
+// Parse is lightweight, it does not validate query and may miss tables of
+// uncommon syntax.
+func Parse(query string) (operation string, tables []string) {
+	return parse(tokenize(query))
+}
+
+func parse(tokens []string) (operation string, tables []string) {
+	for _, stmt := range split(tokens) {
+		op, i, ctes := skipCTEs(stmt)
+		if operation == "" && op != "" && isLetter(op[0]) {
+			operation = strings.ToUpper(op)
+		}
+		for _, table := range tablesOf(stmt, i) {
+			if !ctes[table] && !contains(tables, table) {
+				tables = append(tables, table)
+			}
+		}
+	}
+	return operation, tables
+}
+
+// split splits tokens into statements by semicolons.
+func split(tokens []string) [][]string {
+	var stmts [][]string
+	begin := 0
+	for i, token := range tokens {
+		if token == ";" {
+			if i > begin {
+				stmts = append(stmts, tokens[begin:i])
+			}
+			begin = i + 1
+		}
+	}
+	if begin < len(tokens) {
+		stmts = append(stmts, tokens[begin:])
+	}
+	return stmts
+}
+
+// skipCTEs skips WITH clause at the beginning of stmt, returns the leading
+// keyword of the main statement, its index and the names of common table expressions.
+func skipCTEs(stmt []string) (string, int, map[string]bool) {
+	if stmt[0] != "with" {
+		return stmt[0], 0, nil
+	}
+	ctes := make(map[string]bool)
+	i := 1
+	if i < len(stmt) && stmt[i] == "recursive" {
+		i++
+	}
+	for i < len(stmt) {
+		ctes[unquote(stmt[i])] = true
+		i++
+		if i < len(stmt) && stmt[i] == "(" {
+			i = skipParens(stmt, i)
+		}
+		for i < len(stmt) && (stmt[i] == "as" || stmt[i] == "not" || stmt[i] == "materialized") {
+			i++
+		}
+		if i < len(stmt) && stmt[i] == "(" {
+			i = skipParens(stmt, i)
+		}
+		if i >= len(stmt) || stmt[i] != "," {
+			break
+		}
+		i++
+	}
+	if i >= len(stmt) {
+		return "", i, ctes
+	}
+	return stmt[i], i, ctes
+}
+
+// skipParens returns the index after the parenthesis which closes stmt[i].
+func skipParens(stmt []string, i int) int {
+	depth := 0
+	for ; i < len(stmt); i++ {
+		switch stmt[i] {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return i
+}
+
+// tablesOf returns tables of stmt, including those of common table expressions,
+// main is the index of the main statement.
+func tablesOf(stmt []string, main int) []string {
+	var tables []string
+	args := argFroms(stmt)
+	for i := 0; i < len(stmt); i++ {
+		list := false
+		switch stmt[i] {
+		case "from":
+			if args[i] {
+				continue
+			}
+			list = true
+		case "join", "into":
+		case "update":
+			// not ON DUPLICATE KEY UPDATE or FOR UPDATE.
+			if i != main && (i == 0 || stmt[i-1] != "(") {
+				continue
+			}
+			list = true
+		case "table":
+			// TRUNCATE t without TABLE is handled below.
+		case "truncate":
+			if i+1 < len(stmt) && stmt[i+1] == "table" {
+				continue
+			}
+		default:
+			continue
+		}
+
+		for j := i + 1; j < len(stmt); {
+			j = skipWords(stmt, j, "only", "if", "not", "exists", "low_priority", "ignore")
+			name, next := tableName(stmt, j)
+			if name == "" {
+				break
+			}
+			tables = append(tables, name)
+			j = skipAlias(stmt, next)
+			i = j - 1
+			if !list || j >= len(stmt) || stmt[j] != "," {
+				break
+			}
+			j++
+		}
+	}
+	return tables
+}
+
+// argFuncs are functions which take FROM in their arguments,
+// such as EXTRACT(YEAR FROM ts).
+var argFuncs = map[string]bool{
+	"extract": true, "substring": true, "trim": true, "position": true, "overlay": true,
+}
+
+// argFroms returns indexes of FROM in arguments of argFuncs,
+// those in subqueries of arguments are not included.
+func argFroms(stmt []string) map[int]bool {
+	var froms map[int]bool
+	for i := 0; i+1 < len(stmt); i++ {
+		if !argFuncs[stmt[i]] || stmt[i+1] != "(" {
+			continue
+		}
+		depth := 0
+		for j := i + 1; j < len(stmt); j++ {
+			switch stmt[j] {
+			case "(":
+				depth++
+			case ")":
+				depth--
+			case "from":
+				if depth == 1 {
+					if froms == nil {
+						froms = make(map[int]bool)
+					}
+					froms[j] = true
+				}
+			}
+			if depth == 0 {
+				break
+			}
+		}
+	}
+	return froms
+}
+
+// tableName returns the possibly qualified table name at stmt[i] and the index after it.
+func tableName(stmt []string, i int) (string, int) {
+	if i >= len(stmt) || !isName(stmt[i]) {
+		return "", i
+	}
+	parts := []string{unquote(stmt[i])}
+	i++
+	for i+1 < len(stmt) && stmt[i] == "." && isName(stmt[i+1]) {
+		parts = append(parts, unquote(stmt[i+1]))
+		i += 2
+	}
+	return strings.Join(parts, "."), i
+}
+
+func skipAlias(stmt []string, i int) int {
+	if i < len(stmt) && stmt[i] == "as" {
+		i++
+	}
+	if i < len(stmt) && isName(stmt[i]) {
+		i++
+	}
+	return i
+}
+
+func skipWords(stmt []string, i int, words ...string) int {
+	for i < len(stmt) && contains(words, stmt[i]) {
+		i++
+	}
+	return i
+}
+
+// isName reports whether token is an identifier but not a keyword which may
+// follow a table name.
+func isName(token string) bool {
+	if token == "" {
+		return false
+	}
+	if c := token[0]; c == '"' || c == '`' {
+		return true
+	}
+	if !isLetter(token[0]) {
+		return false
+	}
+	return !keywords[token]
+}
+
+var keywords = map[string]bool{
+	"as": true, "on": true, "using": true, "where": true, "set": true,
+	"join": true, "inner": true, "left": true, "right": true, "full": true,
+	"cross": true, "natural": true, "outer": true, "straight_join": true,
+	"group": true, "order": true, "having": true, "limit": true, "offset": true,
+	"fetch": true, "for": true, "lock": true, "window": true, "partition": true,
+	"union": true, "intersect": true, "except": true, "returning": true,
+	"select": true, "values": true, "value": true, "default": true,
+	"from": true, "into": true, "with": true, "when": true, "then": true,
+	"do": true, "like": true, "add": true, "drop": true, "rename": true,
+	"modify": true, "alter": true, "change": true, "cascade": true,
+}
+
+func unquote(name string) string {
+	if len(name) >= 2 {
+		if c := name[0]; (c == '"' || c == '`') && name[len(name)-1] == c {
+			return name[1 : len(name)-1]
+		}
+	}
+	return name
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package fingerprint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	for _, c := range []struct {
+		query     string
+		operation string
+		tables    []string
+	}{
+		{"SELECT * FROM users WHERE id = 1", "SELECT", []string{"users"}},
+		{"select u.id from `db`.`users` u join orders as o on o.uid = u.id", "SELECT", []string{"db.users", "orders"}},
+		{`SELECT * FROM "Users" a, public."Orders" b`, "SELECT", []string{"Users", "public.Orders"}},
+		{"INSERT IGNORE INTO t (a, b) VALUES (1, 2) ON DUPLICATE KEY UPDATE b = 2", "INSERT", []string{"t"}},
+		{"UPDATE ONLY t SET a = 1 WHERE b IN (SELECT b FROM s)", "UPDATE", []string{"t", "s"}},
+		{"DELETE FROM t WHERE a = 1", "DELETE", []string{"t"}},
+		{
+			"WITH RECURSIVE tree (id) AS (SELECT id FROM nodes), leaf AS NOT MATERIALIZED (SELECT 1) " +
+				"SELECT * FROM tree JOIN leaf ON true",
+			"SELECT", []string{"nodes"},
+		},
+		{"WITH d AS (DELETE FROM a RETURNING *) INSERT INTO b SELECT * FROM d", "INSERT", []string{"a", "b"}},
+		{"WITH u AS (UPDATE t SET a = 1 RETURNING id) SELECT * FROM u FOR UPDATE", "SELECT", []string{"t"}},
+		{"CREATE TABLE IF NOT EXISTS t (id int)", "CREATE", []string{"t"}},
+		{"TRUNCATE t; TRUNCATE TABLE s", "TRUNCATE", []string{"t", "s"}},
+		{"CALL refresh(1)", "CALL", nil},
+		{"SELECT * FROM (SELECT 1) x", "SELECT", nil},
+		{"BEGIN; UPDATE t SET a = 1; COMMIT;", "BEGIN", []string{"t"}},
+		{"SELECT $$a;b$$ FROM t", "SELECT", []string{"t"}},
+		{"SELECT EXTRACT(YEAR FROM ts) FROM events", "SELECT", []string{"events"}},
+		{
+			"SELECT SUBSTRING(name FROM 2), TRIM(LEADING 'x' FROM (SELECT a FROM s)), POSITION('a' IN b) FROM t",
+			"SELECT", []string{"s", "t"},
+		},
+	} {
+		operation, tables := Parse(c.query)
+		require.Equal(t, c.operation, operation, c.query)
+		require.Equal(t, c.tables, tables, c.query)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql/driver"
 	"errors"
+	"sort"
 	"time"
 
 	"github.com/j2gg0s/otsql"
@@ -16,7 +17,12 @@ type Hook struct {
 	*Options
 }
 
-var _ otsql.Hook = (*Hook)(nil)
+var _ otsql.NamedHook = (*Hook)(nil)
+
+// Name is the name of hook, see otsql.DisableHooks.
+const Name = "log"
+
+func (hook *Hook) Name() string { return Name }
 
 func (hook *Hook) Before(ctx context.Context, evt *otsql.Event) context.Context {
 	return ctx
@@ -24,7 +30,7 @@ func (hook *Hook) Before(ctx context.Context, evt *otsql.Event) context.Context
 
 func (hook *Hook) After(ctx context.Context, evt *otsql.Event) {
 	var e *zerolog.Event
-	if time.Since(evt.BeginAt) > hook.Slow {
+	if evt.Duration > hook.Slow {
 		if e == nil {
 			e = hook.Warn(ctx)
 		}
@@ -61,18 +67,56 @@ func (hook *Hook) After(ctx context.Context, evt *otsql.Event) {
 	if evt.Conn != "" {
 		e = e.Str("conn", evt.Conn)
 	}
+	if evt.SessionID != "" {
+		e = e.Str("session", evt.SessionID)
+	}
+	if evt.TxID != "" {
+		e = e.Str("tx", evt.TxID)
+	}
 	if evt.Database != "" {
 		e = e.Str("database", evt.Database)
 	}
 	if evt.Method != "" {
 		e = e.Str("method", string(evt.Method))
 	}
-	e = e.Str("code", otsql.ErrToCode(evt.Err).String()).
-		Dur("latency", time.Since(evt.BeginAt))
+	if len(evt.Tags) > 0 {
+		keys := make([]string, 0, len(evt.Tags))
+		for k := range evt.Tags {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		tags := zerolog.Dict()
+		for _, k := range keys {
+			tags = tags.Str(k, evt.Tags[k])
+		}
+		e = e.Dict("tags", tags)
+	}
+	e = e.Str("code", evt.Code.String()).
+		Dur("latency", evt.Duration)
+	if evt.RowsAffected != nil {
+		e = e.Int64("rows_affected", *evt.RowsAffected)
+	}
+	if evt.LastInsertID != nil {
+		e = e.Int64("last_insert_id", *evt.LastInsertID)
+	}
+	if !evt.RowsAt.IsZero() {
+		// the query is finished by its rows, see otsql.Options.DeferQueryEndB.
+		e = e.Dur("execute_latency", evt.RowsAt.Sub(evt.BeginAt))
+	}
+	if evt.Method == otsql.MethodRowsClose || !evt.RowsAt.IsZero() {
+		e = e.Int64("rows", evt.RowsReturned).
+			Dur("first_row_latency", evt.FirstRowLatency).
+			Dur("fetch_duration", evt.FetchDuration)
+	}
 
-	if hook.Query && evt.Query != "" {
+	if fp := evt.Fingerprint(); fp != nil {
+		e = e.Str("digest", fp.Digest)
+	}
+
+	co := otsql.CallOptionsFromContext(ctx)
+	if co.Query(hook.Query) && evt.Query != "" {
 		e.Str("query", evt.Query)
-		if hook.Args && evt.Args != nil {
+		if co.Args(hook.Args) && evt.Args != nil {
 			e.Interface("params", evt.Args)
 		}
 	}
@@ -120,6 +164,8 @@ func newOptions(opts []Option) *Options {
 			otsql.MethodRowsClose:    zerolog.DebugLevel,
 			otsql.MethodRowsNext:     zerolog.DebugLevel,
 
+			otsql.MethodRowsNextResultSet: zerolog.DebugLevel,
+
 			otsql.MethodExec:         zerolog.InfoLevel,
 			otsql.MethodCreateConn:   zerolog.InfoLevel,
 			otsql.MethodCloseConn:    zerolog.InfoLevel,
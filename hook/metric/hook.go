@@ -2,6 +2,8 @@ package metric
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/j2gg0s/otsql"
@@ -10,32 +12,122 @@ import (
 
 type Hook struct {
 	*Options
+
+	// txs tracks transactions in progress by Event.TxID.
+	txs sync.Map
+}
+
+type txState struct {
+	beginAt    time.Time
+	statements int64
 }
 
-var _ otsql.Hook = (*Hook)(nil)
+var _ otsql.NamedHook = (*Hook)(nil)
+
+// Name is the name of hook, see otsql.DisableHooks.
+const Name = "metric"
+
+func (hook *Hook) Name() string { return Name }
 
 func (hook *Hook) Before(ctx context.Context, evt *otsql.Event) context.Context {
 	return ctx
 }
 
 func (hook *Hook) After(ctx context.Context, evt *otsql.Event) {
-	code := otsql.ErrToCode(evt.Err)
-
-	hook.Latency.WithLabelValues(
-		evt.Instance,
-		evt.Database,
-		string(evt.Method),
-		code.String(),
-	).Observe(float64(time.Since(evt.BeginAt).Microseconds()))
+	labels := prometheus.Labels{
+		sqlInstance: evt.Instance,
+		sqlDatabase: evt.Database,
+		sqlMethod:   string(evt.Method),
+		sqlStatus:   evt.Code.String(),
+	}
+	if hook.Operation {
+		labels[sqlOperation] = evt.Operation()
+	}
+	if hook.Digest {
+		digest := ""
+		if fp := evt.Fingerprint(); fp != nil {
+			digest = fp.Digest
+		}
+		labels[sqlDigest] = digest
+	}
+	for _, tag := range hook.Tags {
+		labels[tag] = evt.Tags[tag]
+	}
+	// New has checked labels of Latency, the sample is dropped if
+	// Latency has more labels than those above.
+	if latency, err := hook.Latency.GetMetricWith(labels); err == nil {
+		latency.Observe(float64(evt.Duration.Microseconds()))
+	}
+
+	if evt.Method == otsql.MethodRowsClose {
+		hook.RowsReturned.WithLabelValues(evt.Instance, evt.Database).
+			Observe(float64(evt.RowsReturned))
+		hook.FetchDuration.WithLabelValues(evt.Instance, evt.Database).
+			Observe(evt.FetchDuration.Seconds())
+	}
+
+	if hook.Transaction && evt.TxID != "" {
+		hook.afterTx(evt)
+	}
+}
+
+const (
+	outcomeCommit       = "commit"
+	outcomeRollback     = "rollback"
+	outcomeCommitFailed = "commit_failed"
+)
+
+func (hook *Hook) afterTx(evt *otsql.Event) {
+	switch evt.Method {
+	case otsql.MethodBegin:
+		if evt.Err == nil {
+			hook.txs.Store(evt.TxID, &txState{beginAt: evt.BeginAt})
+		}
+	case otsql.MethodExec, otsql.MethodQuery:
+		if v, ok := hook.txs.Load(evt.TxID); ok {
+			atomic.AddInt64(&v.(*txState).statements, 1)
+		}
+	case otsql.MethodCommit, otsql.MethodRollback:
+		v, ok := hook.txs.Load(evt.TxID)
+		if !ok {
+			return
+		}
+		hook.txs.Delete(evt.TxID)
+		tx := v.(*txState)
+
+		outcome := outcomeRollback
+		if evt.Method == otsql.MethodCommit {
+			outcome = outcomeCommit
+			if evt.Err != nil {
+				outcome = outcomeCommitFailed
+			}
+		}
+		labels := []string{evt.Instance, evt.Database, outcome}
+
+		hook.TxDuration.WithLabelValues(labels...).
+			Observe(evt.EndAt.Sub(tx.beginAt).Seconds())
+		hook.TxStatements.WithLabelValues(labels...).
+			Observe(float64(atomic.LoadInt64(&tx.statements)))
+		hook.TxTotal.WithLabelValues(labels...).Inc()
+	}
 }
 
 func New(opts ...Option) (*Hook, error) {
 	o := newOptions(opts)
+	if err := o.checkLatency(); err != nil {
+		return nil, err
+	}
 
-	err := o.Registerer.Register(o.Latency)
-	if err != nil {
-		if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
-			return nil, err
+	collectors := []prometheus.Collector{o.Latency, o.RowsReturned, o.FetchDuration}
+	if o.Transaction {
+		collectors = append(collectors, o.TxDuration, o.TxStatements, o.TxTotal)
+	}
+	for _, collector := range collectors {
+		err := o.Registerer.Register(collector)
+		if err != nil {
+			if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+				return nil, err
+			}
 		}
 	}
 
@@ -0,0 +1,113 @@
+package metric
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/j2gg0s/otsql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHook(t *testing.T, opts ...Option) *Hook {
+	txLabels := []string{sqlInstance, sqlDatabase, sqlOutcome}
+	opts = append([]Option{
+		WithRegisterer(prometheus.NewRegistry()),
+		WithLatency(NewLatency()),
+		WithTxDuration(prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "tx_duration"}, txLabels)),
+		WithTxStatements(prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "tx_statements"}, txLabels)),
+		WithTxTotal(prometheus.NewCounterVec(prometheus.CounterOpts{Name: "tx_total"}, txLabels)),
+	}, opts...)
+	hook, err := New(opts...)
+	require.NoError(t, err)
+	return hook
+}
+
+func histogram(t *testing.T, vec *prometheus.HistogramVec, labels ...string) *dto.Histogram {
+	m := &dto.Metric{}
+	require.NoError(t, vec.WithLabelValues(labels...).(prometheus.Metric).Write(m))
+	return m.Histogram
+}
+
+func TestTransaction(t *testing.T) {
+	hook := newTestHook(t, WithTransaction(true))
+
+	begin := time.Unix(0, 0)
+	call := func(method otsql.Method, tx string, err error) {
+		evt := &otsql.Event{Method: method, TxID: tx, BeginAt: begin, EndAt: begin.Add(500 * time.Microsecond), Err: err}
+		evt.Duration = evt.EndAt.Sub(evt.BeginAt)
+		hook.After(hook.Before(context.Background(), evt), evt)
+	}
+
+	call(otsql.MethodBegin, "1", nil)
+	call(otsql.MethodExec, "1", nil)
+	call(otsql.MethodQuery, "1", nil)
+	call(otsql.MethodCommit, "1", nil)
+
+	call(otsql.MethodBegin, "2", nil)
+	call(otsql.MethodRollback, "2", nil)
+
+	call(otsql.MethodBegin, "3", nil)
+	call(otsql.MethodExec, "3", nil)
+	call(otsql.MethodCommit, "3", errors.New("conflict"))
+
+	// failed begin is not tracked.
+	call(otsql.MethodBegin, "4", errors.New("refused"))
+	call(otsql.MethodRollback, "4", nil)
+
+	for outcome, statements := range map[string]float64{
+		outcomeCommit:       2,
+		outcomeRollback:     0,
+		outcomeCommitFailed: 1,
+	} {
+		require.Equal(t, float64(1), testutil.ToFloat64(hook.TxTotal.WithLabelValues("", "", outcome)), outcome)
+		duration := histogram(t, hook.TxDuration, "", "", outcome)
+		require.Equal(t, uint64(1), duration.GetSampleCount(), outcome)
+		require.InDelta(t, 0.0005, duration.GetSampleSum(), 1e-9, outcome)
+		require.Equal(t, statements, histogram(t, hook.TxStatements, "", "", outcome).GetSampleSum(), outcome)
+	}
+	require.Equal(t, 3, testutil.CollectAndCount(hook.TxTotal))
+}
+
+func TestDurationUnits(t *testing.T) {
+	hook := newTestHook(t, WithFetchDuration(prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{Name: "fetch_duration"}, []string{sqlInstance, sqlDatabase},
+	)))
+
+	evt := &otsql.Event{
+		Method:        otsql.MethodRowsClose,
+		Code:          otsql.CodeOK,
+		Duration:      2 * time.Millisecond,
+		FetchDuration: 300 * time.Microsecond,
+	}
+	hook.After(context.Background(), evt)
+
+	latency := histogram(t, hook.Latency, "", "", string(otsql.MethodRowsClose), otsql.CodeOK.String())
+	require.Equal(t, float64(2000), latency.GetSampleSum())
+	require.InDelta(t, 0.0003, histogram(t, hook.FetchDuration, "", "").GetSampleSum(), 1e-9)
+}
+
+func TestLatencyLabels(t *testing.T) {
+	_, err := New(
+		WithRegisterer(prometheus.NewRegistry()),
+		WithLatency(NewLatency()),
+		WithOperation(true),
+		WithTags("tenant"),
+	)
+	require.Error(t, err)
+
+	hook := newTestHook(t, WithLatency(NewLatency("tenant", sqlOperation)), WithOperation(true), WithTags("tenant"))
+	ctx := otsql.WithTags(context.Background(), "tenant", "a")
+	evt := &otsql.Event{Method: otsql.MethodExec, Tags: otsql.TagsFromContext(ctx)}
+	hook.After(ctx, evt)
+	require.Equal(t, uint64(1), histogram(t, hook.Latency, "", "", string(otsql.MethodExec), evt.Code.String(), "a", "").GetSampleCount())
+
+	// the sample is dropped instead of panic if Latency has extra labels.
+	hook = newTestHook(t, WithLatency(NewLatency("extra")))
+	hook.After(ctx, evt)
+	require.Equal(t, 0, testutil.CollectAndCount(hook.Latency))
+}
@@ -1,6 +1,10 @@
 package metric
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 type Option func(*Options)
 
@@ -11,18 +15,65 @@ type Options struct {
 
 	// Latency histogram, default DefaultLatency
 	Latency *prometheus.HistogramVec
+
+	// Operation, if set to true, will add otsql.Event.Operation as label
+	// sql_operation of Latency.
+	Operation bool
+
+	// Digest, if set to true, will add the digest of otsql.Event.Fingerprint
+	// as label sql_digest of Latency, which requires otsql.WithFingerprint.
+	Digest bool
+
+	// Tags is the allowlist of otsql.Event.Tags added as labels of Latency,
+	// absent tags are empty.
+	//
+	// Latency with Operation, Digest or Tags must have labels sql_operation,
+	// sql_digest and tags as well as the default ones, such as created by
+	// NewLatency, New returns error if not.
+	// DefaultLatency is replaced by NewLatency if any of them is set.
+	Tags []string
+
+	// RowsReturned histogram, default DefaultRowsReturned.
+	// Observed on rows close, which requires otsql.WithRowsClose.
+	RowsReturned *prometheus.HistogramVec
+
+	// FetchDuration histogram, default DefaultFetchDuration.
+	// Observed on rows close, which requires otsql.WithRowsClose.
+	FetchDuration *prometheus.HistogramVec
+
+	// Transaction, if set to true, will enable the metrics of transactions,
+	// which are TxDuration, TxStatements and TxTotal.
+	Transaction bool
+
+	// TxDuration histogram, default DefaultTxDuration
+	TxDuration *prometheus.HistogramVec
+
+	// TxStatements histogram, default DefaultTxStatements
+	TxStatements *prometheus.HistogramVec
+
+	// TxTotal counter, default DefaultTxTotal
+	TxTotal *prometheus.CounterVec
 }
 
 func newOptions(opts []Option) *Options {
 	o := &Options{
-		Registerer: prometheus.DefaultRegisterer,
-		Latency:    DefaultLatency,
+		Registerer:    prometheus.DefaultRegisterer,
+		Latency:       DefaultLatency,
+		RowsReturned:  DefaultRowsReturned,
+		FetchDuration: DefaultFetchDuration,
+		TxDuration:    DefaultTxDuration,
+		TxStatements:  DefaultTxStatements,
+		TxTotal:       DefaultTxTotal,
 	}
 
 	for _, opt := range opts {
 		opt(o)
 	}
 
+	if labels := o.latencyLabels(); len(labels) > 0 && o.Latency == DefaultLatency {
+		o.Latency = NewLatency(labels...)
+	}
+
 	return o
 }
 
@@ -40,17 +91,161 @@ func WithLatency(latency *prometheus.HistogramVec) Option {
 	}
 }
 
+// WithOperation if set to true, will add the operation of query as label of Latency.
+func WithOperation(b bool) Option {
+	return func(o *Options) {
+		o.Operation = b
+	}
+}
+
+// WithDigest if set to true, will add the digest of query as label of Latency.
+func WithDigest(b bool) Option {
+	return func(o *Options) {
+		o.Digest = b
+	}
+}
+
+// WithTags sets the allowlist of otsql.Event.Tags added as labels of Latency.
+func WithTags(tags ...string) Option {
+	return func(o *Options) {
+		o.Tags = append(o.Tags, tags...)
+	}
+}
+
+// WithRowsReturned
+func WithRowsReturned(rows *prometheus.HistogramVec) Option {
+	return func(o *Options) {
+		o.RowsReturned = rows
+	}
+}
+
+// WithFetchDuration
+func WithFetchDuration(duration *prometheus.HistogramVec) Option {
+	return func(o *Options) {
+		o.FetchDuration = duration
+	}
+}
+
+// WithTransaction if set to true, will enable the metrics of transactions.
+func WithTransaction(b bool) Option {
+	return func(o *Options) {
+		o.Transaction = b
+	}
+}
+
+// WithTxDuration
+func WithTxDuration(duration *prometheus.HistogramVec) Option {
+	return func(o *Options) {
+		o.TxDuration = duration
+	}
+}
+
+// WithTxStatements
+func WithTxStatements(statements *prometheus.HistogramVec) Option {
+	return func(o *Options) {
+		o.TxStatements = statements
+	}
+}
+
+// WithTxTotal
+func WithTxTotal(total *prometheus.CounterVec) Option {
+	return func(o *Options) {
+		o.TxTotal = total
+	}
+}
+
 var (
-	sqlInstance = "sql_instance"
-	sqlDatabase = "sql_database"
-	sqlMethod   = "sql_method"
-	sqlStatus   = "sql_status"
+	sqlInstance  = "sql_instance"
+	sqlDatabase  = "sql_database"
+	sqlMethod    = "sql_method"
+	sqlStatus    = "sql_status"
+	sqlOutcome   = "sql_outcome"
+	sqlOperation = "sql_operation"
+	sqlDigest    = "sql_digest"
+
+	// durationBuckets are buckets of durations in seconds, from 0.5ms to about 8s.
+	durationBuckets = prometheus.ExponentialBuckets(0.0005, 2, 15)
+
+	DefaultLatency = NewLatency()
 
-	DefaultLatency = prometheus.NewHistogramVec(
+	DefaultRowsReturned = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
-			Name: "go_sql_latency",
-			Help: "The latency of sql calls in milliseconds.",
+			Name:    "go_sql_rows_returned",
+			Help:    "The number of rows returned by queries.",
+			Buckets: prometheus.ExponentialBuckets(1, 4, 10),
+		},
+		[]string{sqlInstance, sqlDatabase},
+	)
+
+	DefaultFetchDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "go_sql_fetch_duration",
+			Help:    "The duration of fetching rows of queries in seconds.",
+			Buckets: durationBuckets,
+		},
+		[]string{sqlInstance, sqlDatabase},
+	)
+
+	DefaultTxDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "go_sql_tx_duration",
+			Help:    "The duration of transactions from begin to commit or rollback in seconds.",
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 16),
+		},
+		[]string{sqlInstance, sqlDatabase, sqlOutcome},
+	)
+
+	DefaultTxStatements = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "go_sql_tx_statements",
+			Help:    "The number of statements executed in transactions.",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 10),
+		},
+		[]string{sqlInstance, sqlDatabase, sqlOutcome},
+	)
+
+	DefaultTxTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "go_sql_tx_total",
+			Help: "The total number of transactions by outcome, which is commit, rollback or commit_failed.",
 		},
-		[]string{sqlInstance, sqlDatabase, sqlMethod, sqlStatus},
+		[]string{sqlInstance, sqlDatabase, sqlOutcome},
 	)
 )
+
+// latencyLabels returns labels of Latency after the default ones.
+func (o *Options) latencyLabels() []string {
+	var labels []string
+	if o.Operation {
+		labels = append(labels, sqlOperation)
+	}
+	if o.Digest {
+		labels = append(labels, sqlDigest)
+	}
+	return append(labels, o.Tags...)
+}
+
+// checkLatency returns error if Latency misses any label observed by hook.
+func (o *Options) checkLatency() error {
+	names := append([]string{sqlInstance, sqlDatabase, sqlMethod, sqlStatus}, o.latencyLabels()...)
+	labels := make(prometheus.Labels, len(names))
+	for _, name := range names {
+		labels[name] = ""
+	}
+	// CurryWith checks names of labels without creating any series.
+	if _, err := o.Latency.CurryWith(labels); err != nil {
+		return fmt.Errorf("metric: Latency must have labels %v: %w", names, err)
+	}
+	return nil
+}
+
+// NewLatency creates latency histogram with extra labels after the default ones.
+func NewLatency(labels ...string) *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "go_sql_latency",
+			Help: "The latency of sql calls in microseconds.",
+		},
+		append([]string{sqlInstance, sqlDatabase, sqlMethod, sqlStatus}, labels...),
+	)
+}
@@ -0,0 +1,112 @@
+// Package sqlcommenter appends trace context and application tags to sql
+// statements as comments, following https://google.github.io/sqlcommenter/spec/.
+//
+// The hook should be placed after hook/trace in otsql.WithHooks, so the span
+// of the current call is already in context when the comment is generated.
+package sqlcommenter
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/j2gg0s/otsql"
+	"github.com/j2gg0s/otsql/fingerprint"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Hook
+type Hook struct {
+	*Options
+}
+
+var _ otsql.NamedHook = (*Hook)(nil)
+
+// Name is the name of hook, see otsql.DisableHooks.
+const Name = "sqlcommenter"
+
+func (hook *Hook) Name() string { return Name }
+
+func New(opts ...Option) *Hook {
+	return &Hook{Options: newOptions(opts)}
+}
+
+func (hook *Hook) Before(ctx context.Context, evt *otsql.Event) context.Context {
+	switch evt.Method {
+	case otsql.MethodExec, otsql.MethodQuery:
+		// prepared statements are already sent to server, the query can't be changed.
+		if evt.Prepared {
+			return ctx
+		}
+	case otsql.MethodPrepare:
+		if hook.SkipPrepare {
+			return ctx
+		}
+	default:
+		return ctx
+	}
+
+	if evt.Query == "" || hasComment(evt.Query) {
+		return ctx
+	}
+
+	if comment := hook.comment(ctx); comment != "" {
+		evt.Query = appendComment(evt.Query, comment)
+	}
+	return ctx
+}
+
+func (hook *Hook) After(ctx context.Context, evt *otsql.Event) {}
+
+func (hook *Hook) comment(ctx context.Context) string {
+	tags := make(map[string]string, len(hook.Tags)+2)
+	for k, v := range hook.Tags {
+		tags[k] = v
+	}
+	if hook.TagsFunc != nil {
+		for k, v := range hook.TagsFunc(ctx) {
+			tags[k] = v
+		}
+	}
+	if hook.TraceContext {
+		propagation.TraceContext{}.Inject(ctx, propagation.MapCarrier(tags))
+	}
+
+	return Format(tags)
+}
+
+// Format serializes tags as a sqlcommenter comment,
+// keys are sorted and both keys and values are url encoded.
+func Format(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, url.QueryEscape(k)+"='"+url.QueryEscape(tags[k])+"'")
+	}
+	return "/*" + strings.Join(pairs, ",") + "*/"
+}
+
+// hasComment reports whether query already has a comment,
+// the spec requires such statements to be left untouched.
+func hasComment(query string) bool {
+	return fingerprint.HasComment(query)
+}
+
+// appendComment appends comment to query, before the trailing semicolon if any.
+func appendComment(query string, comment string) string {
+	trimmed := strings.TrimRight(query, " \t\r\n")
+	if strings.HasSuffix(trimmed, ";") {
+		return strings.TrimRight(trimmed[:len(trimmed)-1], " \t\r\n") + " " + comment + ";"
+	}
+	return trimmed + " " + comment
+}
@@ -0,0 +1,79 @@
+package sqlcommenter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/j2gg0s/otsql"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestBefore(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+	hook := New(
+		WithApplication("otsql"),
+		WithTagsFunc(func(context.Context) map[string]string {
+			return map[string]string{"route": "/users/{id}"}
+		}),
+	)
+
+	fixtures := []struct {
+		method   otsql.Method
+		prepared bool
+		query    string
+		expected string
+	}{
+		{
+			otsql.MethodQuery, false,
+			"SELECT * FROM users",
+			"SELECT * FROM users /*application='otsql',route='%2Fusers%2F%7Bid%7D',traceparent='00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01'*/",
+		},
+		{
+			otsql.MethodExec, false,
+			"DELETE FROM users; ",
+			"DELETE FROM users /*application='otsql',route='%2Fusers%2F%7Bid%7D',traceparent='00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01'*/;",
+		},
+		{
+			otsql.MethodQuery, false,
+			"SELECT 1 /* existing */",
+			"SELECT 1 /* existing */",
+		},
+		{
+			otsql.MethodQuery, false,
+			"SELECT * FROM notes WHERE note = 'a--b' OR note LIKE '%/*%'",
+			"SELECT * FROM notes WHERE note = 'a--b' OR note LIKE '%/*%' /*application='otsql',route='%2Fusers%2F%7Bid%7D',traceparent='00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01'*/",
+		},
+		{
+			otsql.MethodQuery, false,
+			"SELECT 1 -- existing",
+			"SELECT 1 -- existing",
+		},
+		{
+			otsql.MethodQuery, true,
+			"SELECT 1",
+			"SELECT 1",
+		},
+		{
+			otsql.MethodBegin, false,
+			"",
+			"",
+		},
+	}
+
+	for _, f := range fixtures {
+		evt := &otsql.Event{Method: f.method, Prepared: f.prepared, Query: f.query}
+		hook.Before(ctx, evt)
+		require.Equal(t, f.expected, evt.Query)
+	}
+
+	evt := &otsql.Event{Method: otsql.MethodPrepare, Query: "SELECT 1"}
+	New(WithSkipPrepare(true)).Before(ctx, evt)
+	require.Equal(t, "SELECT 1", evt.Query)
+}
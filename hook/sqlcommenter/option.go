@@ -0,0 +1,69 @@
+package sqlcommenter
+
+import "context"
+
+// Option allows for managing sqlcommenter configuration using functional options.
+type Option func(*Options)
+
+// Options holds configuration of our sqlcommenter hook.
+type Options struct {
+	// TraceContext, if set to true, will add traceparent and tracestate
+	// of the span in context to the comment.
+	// Default is true.
+	TraceContext bool
+
+	// SkipPrepare, if set to true, will not comment statements which are
+	// prepared, a changing comment would defeat statement caching.
+	SkipPrepare bool
+
+	// Tags will be added to every comment, such as application or framework.
+	Tags map[string]string
+
+	// TagsFunc extracts tags from context, such as route, controller or action.
+	// They override Tags with the same key.
+	TagsFunc func(context.Context) map[string]string
+}
+
+func newOptions(opts []Option) *Options {
+	o := &Options{
+		TraceContext: true,
+		Tags:         map[string]string{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithTraceContext if set to true, will add traceparent and tracestate to the comment.
+func WithTraceContext(b bool) Option {
+	return func(o *Options) {
+		o.TraceContext = b
+	}
+}
+
+// WithSkipPrepare if set to true, will not comment prepared statements.
+func WithSkipPrepare(b bool) Option {
+	return func(o *Options) {
+		o.SkipPrepare = b
+	}
+}
+
+// WithTag adds a static key/value to every comment.
+func WithTag(key, value string) Option {
+	return func(o *Options) {
+		o.Tags[key] = value
+	}
+}
+
+// WithApplication sets tag application.
+func WithApplication(name string) Option {
+	return WithTag("application", name)
+}
+
+// WithTagsFunc sets function to extract tags from context.
+func WithTagsFunc(fn func(context.Context) map[string]string) Option {
+	return func(o *Options) {
+		o.TagsFunc = fn
+	}
+}
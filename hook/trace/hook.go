@@ -2,25 +2,37 @@ package trace
 
 import (
 	"context"
+	"database/sql"
 	"database/sql/driver"
 	"fmt"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/j2gg0s/otsql"
+	"github.com/j2gg0s/otsql/codes/otelcodes"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
-const (
-	sqlQuery = "sql.query"
-)
-
 type Hook struct {
 	*Options
 
 	Tracer trace.Tracer
+
+	// conns holds synthetic spans of connections by id, see Options.OrphanLinks,
+	// and txs holds spans of transactions by id, see Options.Transaction.
+	mu    sync.Mutex
+	conns map[string]trace.Span
+	txs   map[string]txSpan
+}
+
+type txSpan struct {
+	span trace.Span
+	// parent is the span context in which the transaction begins.
+	parent trace.SpanContext
 }
 
 func New(options ...Option) *Hook {
@@ -30,7 +42,12 @@ func New(options ...Option) *Hook {
 	}
 }
 
-var _ otsql.Hook = (*Hook)(nil)
+var _ otsql.NamedHook = (*Hook)(nil)
+
+// Name is the name of hook, see otsql.DisableHooks.
+const Name = "trace"
+
+func (hook *Hook) Name() string { return Name }
 
 func (hook *Hook) Before(ctx context.Context, evt *otsql.Event) context.Context {
 	switch evt.Method {
@@ -47,7 +64,7 @@ func (hook *Hook) Before(ctx context.Context, evt *otsql.Event) context.Context
 			return ctx
 		}
 	case otsql.MethodRowsNext:
-		if !hook.RowsNext {
+		if !otsql.CallOptionsFromContext(ctx).RowsNext(hook.RowsNext) {
 			return ctx
 		}
 	case otsql.MethodRowsClose:
@@ -60,80 +77,424 @@ func (hook *Hook) Before(ctx context.Context, evt *otsql.Event) context.Context
 		}
 	}
 
+	ctx = hook.withTxSpan(ctx, evt)
+
 	opts := []trace.SpanStartOption{
 		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(evt.BeginAt),
 	}
 
-	attrs := hook.attrsFromSQL(evt.Query, evt.Args)
-	attrs = append(
-		attrs,
-		sqlInstance.String(evt.Instance),
-		sqlDatabase.String(evt.Database),
-	)
+	var links []trace.Link
+	if !hook.AllowRoot && !trace.SpanContextFromContext(ctx).IsValid() {
+		if !hook.OrphanLinks || evt.Conn == "" {
+			return ctx
+		}
+		links = append(links, trace.Link{SpanContext: hook.connSpan(evt).SpanContext()})
+	}
+
+	if hook.Transaction && evt.Method == otsql.MethodBegin && evt.TxID != "" {
+		ctx = hook.startTxSpan(ctx, evt, links)
+	}
+
+	if link, ok := prepareLink(evt); ok {
+		links = append(links, link)
+	}
+	if len(links) > 0 {
+		opts = append(opts, trace.WithLinks(links...))
+	}
+
+	attrs := hook.attrs(ctx, evt)
+	if fp := evt.Fingerprint(); fp != nil {
+		attrs = append(attrs, sqlDigest.String(fp.Digest))
+	}
+	if evt.StmtID != "" {
+		attrs = append(attrs, sqlStmt.String(evt.StmtID))
+	}
+	if evt.Prepared {
+		attrs = append(
+			attrs,
+			dbStatementPrepared.Bool(true),
+			sqlStmtExecutions.Int64(evt.StmtExecutions),
+		)
+	}
+	for k, v := range evt.Tags {
+		attrs = append(attrs, attribute.String(sqlTag+k, v))
+	}
 	opts = append(opts, trace.WithAttributes(attrs...))
 
-	spanName := hook.SpanNameFormatter(ctx, string(evt.Method), evt.Query)
-	ctx, _ = hook.Tracer.Start(ctx, spanName, opts...)
+	name := spanName(evt)
+	if hook.SpanNameFormatter != nil {
+		name = hook.SpanNameFormatter(ctx, string(evt.Method), evt.Query)
+	}
+	ctx, span := hook.Tracer.Start(ctx, name, opts...)
+	if evt.Method == otsql.MethodPrepare {
+		evt.SetValue(prepareSpanKey{}, span.SpanContext())
+	}
 
-	return ctx
+	return context.WithValue(ctx, spanKey{}, eventSpan{evt: evt, span: span})
+}
+
+// prepareSpanKey is the key of the span context of Prepare set on its event.
+type prepareSpanKey struct{}
+
+// prepareLink returns the link to the span of Prepare which created the
+// statement of evt, if the span has been started by hook.
+func prepareLink(evt *otsql.Event) (trace.Link, bool) {
+	if !evt.Prepared || evt.Prepare == nil {
+		return trace.Link{}, false
+	}
+	sc, ok := evt.Prepare.Value(prepareSpanKey{}).(trace.SpanContext)
+	if !ok {
+		return trace.Link{}, false
+	}
+	return trace.Link{SpanContext: sc}, true
 }
 
-func (h *Hook) After(ctx context.Context, evt *otsql.Event) {
-	span := trace.SpanFromContext(ctx)
+// spanKey is the context key of eventSpan.
+type spanKey struct{}
+
+// eventSpan is the span started by Before for evt, so After does not end
+// the span of parent, such as the span of query for RowsNext, if Before
+// has skipped evt.
+type eventSpan struct {
+	evt  *otsql.Event
+	span trace.Span
+}
+
+func (hook *Hook) After(ctx context.Context, evt *otsql.Event) {
+	if evt.Method == otsql.MethodCloseConn ||
+		(evt.Method == otsql.MethodCreateConn && evt.Err != nil) {
+		defer hook.endConnSpan(evt)
+	}
+	if evt.Method == otsql.MethodCommit || evt.Method == otsql.MethodRollback ||
+		(evt.Method == otsql.MethodBegin && evt.Err != nil) {
+		defer hook.endTxSpan(evt)
+	}
+
+	es, ok := ctx.Value(spanKey{}).(eventSpan)
+	if !ok || es.evt != evt {
+		return
+	}
+	span := es.span
 	if !span.IsRecording() {
 		return
 	}
 
-	code, err := codes.Ok, evt.Err
-	if err != nil {
-		span.RecordError(err)
-		code = codes.Error
+	if evt.RowsAffected != nil {
+		span.SetAttributes(dbRowsAffected.Int64(*evt.RowsAffected))
+	}
+	if evt.LastInsertID != nil {
+		span.SetAttributes(dbLastInsertID.Int64(*evt.LastInsertID))
+	}
+	if !evt.RowsAt.IsZero() {
+		// the query is finished by its rows, see otsql.Options.DeferQueryEndB.
+		span.AddEvent("executed", trace.WithTimestamp(evt.RowsAt))
+		span.AddEvent("fetched", trace.WithTimestamp(evt.EndAt))
+	}
+	if evt.Method == otsql.MethodRowsClose || !evt.RowsAt.IsZero() {
+		if hook.stable() {
+			span.SetAttributes(dbResponseReturnedRows.Int64(evt.RowsReturned))
+		}
+		if hook.legacy() {
+			span.SetAttributes(sqlRowsReturned.Int64(evt.RowsReturned))
+		}
+		span.SetAttributes(
+			sqlFirstRowLatency.Float64(float64(evt.FirstRowLatency)/float64(time.Millisecond)),
+			sqlFetchDuration.Float64(float64(evt.FetchDuration)/float64(time.Millisecond)),
+		)
+	}
+	if evt.Err != nil {
+		span.RecordError(evt.Err)
+		if code := otsql.ErrorStatusCode(evt.Err); code != "" && hook.stable() {
+			span.SetAttributes(dbResponseStatusCode.String(code))
+		}
+	}
+	span.SetStatus(otelcodes.Status(evt.Code))
+	span.End(trace.WithTimestamp(evt.EndAt))
+}
+
+// connSpan returns the synthetic span of connection of evt, starts it if absent.
+func (hook *Hook) connSpan(evt *otsql.Event) trace.Span {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	if span, ok := hook.conns[evt.Conn]; ok {
+		return span
+	}
+	attrs := []attribute.KeyValue{sqlConn.String(evt.Conn)}
+	if evt.System != "" {
+		attrs = append(attrs, dbSystem.String(evt.System))
+	}
+	if evt.Database != "" {
+		attrs = append(attrs, dbNamespace.String(evt.Database))
+	}
+	_, span := hook.Tracer.Start(
+		context.Background(), "conn",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(evt.BeginAt),
+		trace.WithAttributes(attrs...),
+	)
+	if hook.conns == nil {
+		hook.conns = make(map[string]trace.Span)
+	}
+	hook.conns[evt.Conn] = span
+	return span
+}
+
+// endConnSpan ends the synthetic span of connection of evt if there is one.
+func (hook *Hook) endConnSpan(evt *otsql.Event) {
+	hook.mu.Lock()
+	span, ok := hook.conns[evt.Conn]
+	delete(hook.conns, evt.Conn)
+	hook.mu.Unlock()
+
+	if ok {
+		span.End(trace.WithTimestamp(evt.EndAt))
+	}
+}
+
+// startTxSpan starts span of the transaction begun by evt, which becomes
+// the parent of spans of evt and statements executed in the transaction.
+func (hook *Hook) startTxSpan(ctx context.Context, evt *otsql.Event, links []trace.Link) context.Context {
+	attrs := []attribute.KeyValue{sqlTx.String(evt.TxID)}
+	if evt.TxOptions != nil {
+		attrs = append(
+			attrs,
+			sqlTxIsolation.String(sql.IsolationLevel(evt.TxOptions.Isolation).String()),
+			sqlTxReadOnly.Bool(evt.TxOptions.ReadOnly),
+		)
+	}
+	parent := trace.SpanContextFromContext(ctx)
+	ctx, span := hook.Tracer.Start(
+		ctx, "transaction",
+		trace.WithTimestamp(evt.BeginAt),
+		trace.WithAttributes(attrs...),
+		trace.WithLinks(links...),
+	)
+
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+	if hook.txs == nil {
+		hook.txs = make(map[string]txSpan)
+	}
+	hook.txs[evt.TxID] = txSpan{span: span, parent: parent}
+	return ctx
+}
+
+// withTxSpan returns ctx with span of the transaction of evt as parent,
+// for statements, commit and rollback called in the context where the
+// transaction begins. Rows keep their query span as parent.
+func (hook *Hook) withTxSpan(ctx context.Context, evt *otsql.Event) context.Context {
+	if evt.TxID == "" {
+		return ctx
+	}
+	switch evt.Method {
+	case otsql.MethodExec, otsql.MethodQuery, otsql.MethodPrepare,
+		otsql.MethodCommit, otsql.MethodRollback:
+	default:
+		return ctx
+	}
+
+	hook.mu.Lock()
+	tx, ok := hook.txs[evt.TxID]
+	hook.mu.Unlock()
+	if !ok {
+		return ctx
+	}
+	if current := trace.SpanContextFromContext(ctx); current.IsValid() && !current.Equal(tx.parent) {
+		// already under a span started inside the transaction.
+		return ctx
+	}
+	return trace.ContextWithSpan(ctx, tx.span)
+}
+
+// endTxSpan ends span of the transaction of evt with its outcome.
+func (hook *Hook) endTxSpan(evt *otsql.Event) {
+	hook.mu.Lock()
+	tx, ok := hook.txs[evt.TxID]
+	delete(hook.txs, evt.TxID)
+	hook.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	outcome := "rollback"
+	switch {
+	case evt.Method == otsql.MethodBegin:
+		outcome = "begin_failed"
+	case evt.Method == otsql.MethodCommit && evt.Err != nil:
+		outcome = "commit_failed"
+	case evt.Method == otsql.MethodCommit:
+		outcome = "commit"
+	}
+	tx.span.SetAttributes(sqlTxOutcome.String(outcome))
+	if evt.Err != nil {
+		tx.span.SetStatus(otelcodes.Status(evt.Code))
 	}
-	span.SetStatus(code, otsql.ErrToCode(err).String())
-	span.End()
+	tx.span.End(trace.WithTimestamp(evt.EndAt))
+}
+
+// spanName is operation and the first table, or method if operation is unknown.
+func spanName(evt *otsql.Event) string {
+	operation, tables := evt.Operation(), evt.Tables()
+	if operation == "" {
+		return string(evt.Method)
+	}
+	if len(tables) == 0 {
+		return operation
+	}
+	return operation + " " + tables[0]
 }
 
 var (
 	attributeUnknownArgs = attribute.String("otsql.warning", "unknown args type")
 )
 
-func (hook *Hook) attrsFromSQL(query string, args interface{}) []attribute.KeyValue {
+func (hook *Hook) attrs(ctx context.Context, evt *otsql.Event) []attribute.KeyValue {
 	var attrs []attribute.KeyValue
 	if len(hook.DefaultAttributes) > 0 {
 		attrs = append(attrs, hook.DefaultAttributes...)
 	}
 
-	if hook.Query && len(query) > 0 {
-		attrs = append(attrs, attribute.String(sqlQuery, query))
+	co := otsql.CallOptionsFromContext(ctx)
+	recordQuery := co.Query(hook.Query) && len(evt.Query) > 0
+	// same as Options.QueryParams, args are recorded only with the query.
+	recordArgs := recordQuery && co.Args(hook.QueryParams) && evt.Args != nil
+
+	if evt.System != "" && (hook.stable() || hook.v110()) {
+		attrs = append(attrs, dbSystem.String(evt.System))
 	}
 
-	if hook.QueryParams && args != nil {
-		switch sqlArgs := args.(type) {
-		case []driver.NamedValue:
-			for _, arg := range sqlArgs {
-				if len(arg.Name) > 0 {
-					attrs = append(attrs, argToAttr(arg.Name, arg.Value))
-				} else {
-					attrs = append(attrs, argToAttr(strconv.Itoa(arg.Ordinal), arg.Value))
-				}
+	if hook.stable() {
+		if evt.Database != "" {
+			attrs = append(attrs, dbNamespace.String(evt.Database))
+		}
+		if operation := evt.Operation(); operation != "" {
+			attrs = append(attrs, dbOperationName.String(operation))
+		}
+		if tables := evt.Tables(); len(tables) == 1 {
+			attrs = append(attrs, dbCollectionName.String(tables[0]))
+		}
+		if ci := evt.ConnInfo; ci != nil && ci.Host != "" {
+			attrs = append(attrs, serverAddress.String(ci.Host))
+			if ci.Port > 0 {
+				attrs = append(attrs, serverPort.Int(ci.Port))
 			}
-		case []driver.Value:
-			for i, arg := range sqlArgs {
-				attrs = append(attrs, argToAttr(strconv.Itoa(i), arg))
+		}
+		if recordQuery {
+			attrs = append(attrs, dbQueryText.String(evt.Query))
+		}
+		if recordArgs {
+			attrs = appendArgs(attrs, dbQueryParameter, evt.Args)
+		}
+	}
+
+	if hook.v110() {
+		if evt.Database != "" {
+			attrs = append(attrs, semconv.DBNameKey.String(evt.Database))
+		}
+		if operation := evt.Operation(); operation != "" {
+			attrs = append(attrs, semconv.DBOperationKey.String(operation))
+		}
+		if tables := evt.Tables(); len(tables) == 1 {
+			attrs = append(attrs, semconv.DBSQLTableKey.String(tables[0]))
+		}
+		if ci := evt.ConnInfo; ci != nil && ci.Host != "" {
+			attrs = append(attrs, semconv.NetPeerNameKey.String(ci.Host))
+			if ci.Port > 0 {
+				attrs = append(attrs, semconv.NetPeerPortKey.Int(ci.Port))
 			}
-		default:
-			attrs = append(attrs, attributeUnknownArgs)
+		}
+		if recordQuery {
+			attrs = append(attrs, semconv.DBStatementKey.String(evt.Query))
+		}
+	}
+
+	if hook.legacy() {
+		attrs = append(
+			attrs,
+			sqlInstance.String(evt.Instance),
+			sqlDatabase.String(evt.Database),
+		)
+		if recordQuery {
+			attrs = append(attrs, sqlQuery.String(evt.Query))
+		}
+		if recordArgs {
+			attrs = appendArgs(attrs, sqlArg, evt.Args)
 		}
 	}
 
 	return attrs
 }
 
-func argToAttr(k string, v driver.Value) attribute.KeyValue {
-	return attribute.String(fmt.Sprintf("sql.arg.%s", k), fmt.Sprintf("%v", v))
+// appendArgs appends args as attributes which keys are prefix followed by
+// the name or position of arg.
+func appendArgs(attrs []attribute.KeyValue, prefix string, args interface{}) []attribute.KeyValue {
+	switch sqlArgs := args.(type) {
+	case []driver.NamedValue:
+		for _, arg := range sqlArgs {
+			if len(arg.Name) > 0 {
+				attrs = append(attrs, argToAttr(prefix, arg.Name, arg.Value))
+			} else {
+				attrs = append(attrs, argToAttr(prefix, strconv.Itoa(arg.Ordinal), arg.Value))
+			}
+		}
+	case []driver.Value:
+		for i, arg := range sqlArgs {
+			attrs = append(attrs, argToAttr(prefix, strconv.Itoa(i), arg))
+		}
+	default:
+		attrs = append(attrs, attributeUnknownArgs)
+	}
+	return attrs
+}
+
+func argToAttr(prefix string, k string, v driver.Value) attribute.KeyValue {
+	return attribute.String(prefix+k, fmt.Sprintf("%v", v))
 }
 
+// Keys of OpenTelemetry database semantic conventions,
+// see https://opentelemetry.io/docs/specs/semconv/database/database-spans/.
+var (
+	dbSystem               = attribute.Key("db.system")
+	dbNamespace            = attribute.Key("db.namespace")
+	dbCollectionName       = attribute.Key("db.collection.name")
+	dbOperationName        = attribute.Key("db.operation.name")
+	dbQueryText            = attribute.Key("db.query.text")
+	dbQueryParameter       = "db.query.parameter."
+	dbResponseStatusCode   = attribute.Key("db.response.status_code")
+	dbResponseReturnedRows = attribute.Key("db.response.returned_rows")
+	serverAddress          = attribute.Key("server.address")
+	serverPort             = attribute.Key("server.port")
+)
+
+// Keys of SemConvLegacy.
 var (
-	sqlInstance = attribute.Key("sql.instance")
-	sqlDatabase = attribute.Key("sql.database")
+	sqlInstance     = attribute.Key("sql.instance")
+	sqlDatabase     = attribute.Key("sql.database")
+	sqlQuery        = attribute.Key("sql.query")
+	sqlArg          = "sql.arg."
+	sqlRowsReturned = attribute.Key("sql.rows_returned")
+)
+
+var (
+	sqlDigest = attribute.Key("sql.digest")
+	// sqlTag prefixes keys of otsql.Event.Tags.
+	sqlTag  = "sql.tag."
+	sqlConn = attribute.Key("sql.conn")
+
+	sqlStmt             = attribute.Key("sql.stmt")
+	sqlStmtExecutions   = attribute.Key("sql.stmt.executions")
+	dbStatementPrepared = attribute.Key("db.statement.prepared")
+
+	sqlTx          = attribute.Key("sql.tx")
+	sqlTxIsolation = attribute.Key("sql.tx.isolation")
+	sqlTxReadOnly  = attribute.Key("sql.tx.read_only")
+	sqlTxOutcome   = attribute.Key("sql.tx.outcome")
+
+	sqlFirstRowLatency = attribute.Key("sql.first_row_latency_ms")
+	sqlFetchDuration   = attribute.Key("sql.fetch_duration_ms")
+
+	dbRowsAffected = attribute.Key("db.rows_affected")
+	dbLastInsertID = attribute.Key("db.last_insert_id")
 )
@@ -0,0 +1,257 @@
+package trace
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/j2gg0s/otsql"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+)
+
+func newTestHook(opts ...Option) (*Hook, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	hook := New(opts...)
+	hook.Tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	return hook, recorder
+}
+
+func call(hook *Hook, ctx context.Context, evt *otsql.Event) {
+	evt.BeginAt = time.Now()
+	ctx = hook.Before(ctx, evt)
+	evt.EndAt = time.Now()
+	hook.After(ctx, evt)
+}
+
+// fakeConn supports only methods without context.
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+
+func (fakeConn) Close() error { return nil }
+
+func (fakeConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+func (fakeConn) Exec(query string, args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func TestAllowRoot(t *testing.T) {
+	for _, allowRoot := range []bool{false, true} {
+		hook, recorder := newTestHook(WithAllowRoot(allowRoot))
+		call(hook, context.Background(), &otsql.Event{Method: otsql.MethodExec})
+		call(hook, context.Background(), &otsql.Event{Method: otsql.MethodQuery})
+
+		// Exec without context calls hooks with context.Background().
+		conn := otsql.WrapConn(fakeConn{}, otsql.WithHooks(hook))
+		_, err := conn.(driver.Execer).Exec("DELETE FROM t", nil) // nolint
+		require.NoError(t, err)
+
+		if allowRoot {
+			require.Len(t, recorder.Ended(), 3)
+		} else {
+			require.Empty(t, recorder.Ended())
+		}
+	}
+}
+
+// skipConn leaves the call with context to Exec.
+type skipConn struct{ fakeConn }
+
+func (skipConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return nil, driver.ErrSkip
+}
+
+func TestErrSkip(t *testing.T) {
+	hook, recorder := newTestHook(WithAllowRoot(true))
+	conn := otsql.WrapConn(skipConn{}, otsql.WithHooks(hook))
+
+	_, err := conn.(driver.ExecerContext).ExecContext(context.Background(), "DELETE FROM t", nil)
+	require.Equal(t, driver.ErrSkip, err)
+	require.Empty(t, recorder.Ended())
+
+	// database/sql retries with Exec.
+	_, err = conn.(driver.Execer).Exec("DELETE FROM t", nil) // nolint
+	require.NoError(t, err)
+	require.Len(t, recorder.Ended(), 1)
+}
+
+func TestSkippedEventKeepsParent(t *testing.T) {
+	hook, recorder := newTestHook()
+	ctx, parent := hook.Tracer.Start(context.Background(), "parent")
+
+	// RowsNext is disabled, After must not end the parent span.
+	call(hook, ctx, &otsql.Event{Method: otsql.MethodRowsNext})
+	require.Empty(t, recorder.Ended())
+
+	call(hook, ctx, &otsql.Event{Method: otsql.MethodExec})
+	parent.End()
+	ended := recorder.Ended()
+	require.Len(t, ended, 2)
+	require.Equal(t, parent.SpanContext().SpanID(), ended[0].Parent().SpanID())
+}
+
+func TestOrphanLinks(t *testing.T) {
+	hook, recorder := newTestHook(WithOrphanLinks(true))
+
+	call(hook, context.Background(), &otsql.Event{Method: otsql.MethodExec, Conn: "1"})
+	call(hook, context.Background(), &otsql.Event{Method: otsql.MethodQuery, Conn: "1"})
+	require.Len(t, recorder.Ended(), 2)
+
+	call(hook, context.Background(), &otsql.Event{Method: otsql.MethodCloseConn, Conn: "1"})
+	ended := recorder.Ended()
+	require.Len(t, ended, 4)
+
+	conn := ended[3]
+	require.Equal(t, "conn", conn.Name())
+	for _, span := range ended[:3] {
+		require.False(t, span.Parent().IsValid())
+		require.Len(t, span.Links(), 1)
+		require.Equal(t, conn.SpanContext().SpanID(), span.Links()[0].SpanContext.SpanID())
+	}
+}
+
+func TestDeferredQueryEvents(t *testing.T) {
+	hook, recorder := newTestHook(WithAllowRoot(true))
+
+	begin := time.Now()
+	evt := &otsql.Event{Method: otsql.MethodQuery, BeginAt: begin}
+	ctx := hook.Before(context.Background(), evt)
+	evt.RowsAt, evt.EndAt = begin.Add(time.Millisecond), begin.Add(time.Second)
+	evt.RowsReturned = 10
+	hook.After(ctx, evt)
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 1)
+	require.Equal(t, begin.Add(time.Second), ended[0].EndTime())
+	events := ended[0].Events()
+	require.Len(t, events, 2)
+	require.Equal(t, "executed", events[0].Name)
+	require.Equal(t, begin.Add(time.Millisecond), events[0].Time)
+	require.Equal(t, "fetched", events[1].Name)
+}
+
+func TestTransactionSpan(t *testing.T) {
+	hook, recorder := newTestHook(WithTransaction(true))
+	ctx, parent := hook.Tracer.Start(context.Background(), "parent")
+
+	opts := &driver.TxOptions{Isolation: driver.IsolationLevel(sql.LevelSerializable)}
+	call(hook, ctx, &otsql.Event{Method: otsql.MethodBegin, TxID: "1", TxOptions: opts})
+	call(hook, ctx, &otsql.Event{Method: otsql.MethodExec, TxID: "1", TxOptions: opts})
+	call(hook, ctx, &otsql.Event{Method: otsql.MethodCommit, TxID: "1", TxOptions: opts})
+	parent.End()
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 5)
+	begin, exec, commit, tx := ended[0], ended[1], ended[2], ended[3]
+	require.Equal(t, "transaction", tx.Name())
+	require.Equal(t, parent.SpanContext().SpanID(), tx.Parent().SpanID())
+	for _, span := range []sdktrace.ReadOnlySpan{begin, exec, commit} {
+		require.Equal(t, tx.SpanContext().SpanID(), span.Parent().SpanID())
+	}
+	require.Contains(t, tx.Attributes(), sqlTxOutcome.String("commit"))
+	require.Contains(t, tx.Attributes(), sqlTxIsolation.String("Serializable"))
+}
+
+func TestPrepareLink(t *testing.T) {
+	hook, recorder := newTestHook()
+	ctx, parent := hook.Tracer.Start(context.Background(), "parent")
+
+	prepare := &otsql.Event{Method: otsql.MethodPrepare, StmtID: "1", BeginAt: time.Now()}
+	prepareCtx := hook.Before(ctx, prepare)
+	prepare.EndAt = time.Now()
+	hook.After(prepareCtx, prepare)
+
+	for i := int64(1); i <= 2; i++ {
+		call(hook, ctx, &otsql.Event{
+			Method:         otsql.MethodExec,
+			Prepared:       true,
+			StmtID:         "1",
+			StmtExecutions: i,
+			Prepare:        prepare,
+		})
+	}
+	parent.End()
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 4)
+	require.Contains(t, ended[0].Attributes(), sqlStmt.String("1"))
+	for i, span := range ended[1:3] {
+		require.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		require.Len(t, span.Links(), 1)
+		require.Equal(t, ended[0].SpanContext().SpanID(), span.Links()[0].SpanContext.SpanID())
+		require.Contains(t, span.Attributes(), dbStatementPrepared.Bool(true))
+		require.Contains(t, span.Attributes(), sqlStmtExecutions.Int64(int64(i+1)))
+	}
+}
+
+func TestTags(t *testing.T) {
+	hook, recorder := newTestHook(WithAllowRoot(true), WithSemConv(SemConvStable))
+	call(hook, context.Background(), &otsql.Event{
+		Method: otsql.MethodExec,
+		System: otsql.SystemMySQL,
+		Tags:   map[string]string{"tenant": "j2gg0s", "db.system": "fake"},
+	})
+
+	attrs := recorder.Ended()[0].Attributes()
+	require.Contains(t, attrs, dbSystem.String(otsql.SystemMySQL))
+	require.Contains(t, attrs, attribute.String("sql.tag.tenant", "j2gg0s"))
+	require.Contains(t, attrs, attribute.String("sql.tag.db.system", "fake"))
+}
+
+func TestSemConv(t *testing.T) {
+	evt := func() *otsql.Event {
+		return &otsql.Event{
+			Method:   otsql.MethodExec,
+			Query:    "DELETE FROM t",
+			Instance: "localhost:3306",
+			Database: "test",
+			System:   otsql.SystemMySQL,
+		}
+	}
+	keys := func(mode SemConv) []attribute.Key {
+		hook, recorder := newTestHook(WithAllowRoot(true), WithQuery(true), WithSemConv(mode))
+		call(hook, context.Background(), evt())
+		var keys []attribute.Key
+		for _, attr := range recorder.Ended()[0].Attributes() {
+			keys = append(keys, attr.Key)
+		}
+		return keys
+	}
+
+	// SemConvLegacy is the default.
+	hook, recorder := newTestHook(WithAllowRoot(true), WithQuery(true))
+	call(hook, context.Background(), evt())
+	require.Len(t, recorder.Ended()[0].Attributes(), len(keys(SemConvLegacy)))
+
+	dup := keys(SemConvDup)
+	for _, key := range []attribute.Key{
+		dbSystem, dbNamespace, dbQueryText,
+		semconv.DBNameKey, semconv.DBStatementKey,
+		sqlInstance, sqlDatabase, sqlQuery,
+	} {
+		require.Contains(t, dup, key)
+	}
+
+	stable := keys(SemConvStable)
+	require.Contains(t, stable, dbQueryText)
+	require.NotContains(t, stable, semconv.DBStatementKey)
+	require.NotContains(t, stable, sqlQuery)
+
+	v110 := keys(SemConvV110)
+	require.Contains(t, v110, dbSystem)
+	require.Contains(t, v110, semconv.DBNameKey)
+	require.Contains(t, v110, semconv.DBStatementKey)
+	require.NotContains(t, v110, dbNamespace)
+	require.NotContains(t, v110, sqlDatabase)
+
+	legacy := keys(SemConvLegacy)
+	require.Contains(t, legacy, sqlQuery)
+	require.NotContains(t, legacy, dbSystem)
+}
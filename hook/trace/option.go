@@ -20,6 +20,12 @@ type Options struct {
 	// in context or when using methods not taking context.
 	AllowRoot bool
 
+	// OrphanLinks, if set to true, will create spans without parent instead of
+	// dropping them when AllowRoot is false. Each of them links to a synthetic
+	// span of its connection, which starts with the first orphan span and
+	// ends when the connection is closed.
+	OrphanLinks bool
+
 	// Query, if set to true, will enable recording of sql queries in spans.
 	// Only allow this if it is safe to have queries recorded with respect to
 	// security.
@@ -31,6 +37,11 @@ type Options struct {
 	// This setting is a noop if the Query option is set to false.
 	QueryParams bool
 
+	// Transaction, if set to true, will create a span of each transaction from
+	// begin to commit or rollback, which is the parent of spans of begin,
+	// statements, commit and rollback of the transaction.
+	Transaction bool
+
 	// Ping, if set to true, will enable the creation of spans on Ping requests.
 	Ping bool
 
@@ -55,7 +66,8 @@ type Options struct {
 	ResetSession bool
 
 	// SpanNameFormatter will be called to produce span's name.
-	// Default use method as span name
+	// Default use operation and the first table as span name, such as
+	// SELECT users, or method if absent.
 	SpanNameFormatter func(ctx context.Context, method string, query string) string
 
 	// DefaultAttributes will be set to each span as default.
@@ -63,6 +75,41 @@ type Options struct {
 
 	// InstanceName identifies database.
 	InstanceName string
+
+	// SemConv selects keys of span attributes, default SemConvLegacy.
+	SemConv SemConv
+}
+
+// SemConv selects keys of span attributes.
+type SemConv int
+
+const (
+	// SemConvLegacy records keys of previous versions of the hook,
+	// which are sql.instance, sql.database, sql.query and sql.arg.N.
+	SemConvLegacy SemConv = iota
+	// SemConvStable records the current OpenTelemetry database semantic
+	// conventions, such as db.system, db.namespace, db.query.text and server.address.
+	SemConvStable
+	// SemConvV110 records semantic conventions v1.10, which go.opentelemetry.io/otel
+	// v1.7 ships, such as db.system, db.name, db.statement and net.peer.name.
+	SemConvV110
+	// SemConvDup records keys of all the above, so dashboards built on keys
+	// of previous versions keep working during migration.
+	// It records the query three times in every span, which triples the
+	// size of spans of long queries.
+	SemConvDup
+)
+
+func (o *Options) stable() bool {
+	return o.SemConv == SemConvStable || o.SemConv == SemConvDup
+}
+
+func (o *Options) v110() bool {
+	return o.SemConv == SemConvV110 || o.SemConv == SemConvDup
+}
+
+func (o *Options) legacy() bool {
+	return o.SemConv == SemConvLegacy || o.SemConv == SemConvDup
 }
 
 func newOptions(opts []Option) *Options {
@@ -71,12 +118,6 @@ func newOptions(opts []Option) *Options {
 		opt(o)
 	}
 
-	if o.SpanNameFormatter == nil {
-		o.SpanNameFormatter = func(ctx context.Context, method string, query string) string {
-			return method
-		}
-	}
-
 	if o.QueryParams && !o.Query {
 		o.QueryParams = false
 	}
@@ -105,6 +146,22 @@ func WithAllowRoot(b bool) Option {
 	}
 }
 
+// WithOrphanLinks if set to true, will link spans without parent to a synthetic
+// span of their connection instead of dropping them, see Options.OrphanLinks.
+func WithOrphanLinks(b bool) Option {
+	return func(o *Options) {
+		o.OrphanLinks = b
+	}
+}
+
+// WithTransaction if set to true, will nest spans of statements under
+// a span of their transaction.
+func WithTransaction(b bool) Option {
+	return func(o *Options) {
+		o.Transaction = b
+	}
+}
+
 // WithPing if set to true, will enable the creation of spans on Ping requests.
 func WithPing(b bool) Option {
 	return func(o *Options) {
@@ -191,3 +248,10 @@ func WithSpanNameFormatter(formatter func(context.Context, string, string) strin
 		o.SpanNameFormatter = formatter
 	}
 }
+
+// WithSemConv selects keys of span attributes, see SemConv.
+func WithSemConv(semconv SemConv) Option {
+	return func(o *Options) {
+		o.SemConv = semconv
+	}
+}
@@ -1,5 +1,7 @@
 package otsql
 
+import "time"
+
 // Option allows for managing otsql configuration using functional options.
 type Option func(*Options)
 
@@ -14,6 +16,13 @@ type Options struct {
 	// Database, default parse from dsn.
 	Database string
 
+	// System is the database system, such as SystemMySQL, default inferred
+	// from the wrapped driver or parsed from dsn.
+	System string
+
+	// ConnInfo, parsed from dsn, nil if the dsn is unknown.
+	ConnInfo *ConnInfo
+
 	// PingB, if set to true, will enable the hook of Ping requests.
 	PingB bool
 
@@ -23,18 +32,51 @@ type Options struct {
 	// LastInsertIdB, if set to true, will enable the hook LastInsertId calls.
 	LastInsertIdB bool
 
+	// ExecResultB, if set to true, will read RowsAffected and LastInsertId
+	// right after a successful exec, see Event.RowsAffected.
+	ExecResultB bool
+
+	// FingerprintB, if set to true, will enable Event.Fingerprint of calls
+	// with query, which is computed on first use.
+	FingerprintB bool
+
+	// DeferQueryEndB, if set to true, will finish query events when their rows
+	// are exhausted or closed, instead of when the driver returns rows,
+	// so hooks report the time of fetching rows as well, see Event.RowsAt.
+	DeferQueryEndB bool
+
 	// RowsNextB, if set to true, will enable the hook of calls.
 	// This can result in many calls.
 	RowsNextB bool
 
 	// RowsCloseB, if set to true, will enable the hook of RowsClose calls.
+	// The event summarizes the rows fetched, see Event.RowsReturned.
 	RowsCloseB bool
 
 	// ResetSessionB, if set to true, will enable the hook of ResetSession calls.
 	ResetSessionB bool
 
+	// StmtCloseB, if set to true, will enable the hook of Close calls of
+	// prepared statements.
+	StmtCloseB bool
+
 	// Hooks, enabled hooks.
 	Hooks []Hook
+
+	// Interceptors, wrap every driver call in order, the first one is the outermost.
+	Interceptors []Interceptor
+
+	// ErrorClassifier, classify error of driver call to Event.Code,
+	// default DefaultErrorClassifier.
+	ErrorClassifier ErrorClassifier
+
+	// Clock returns the current time, default time.Now.
+	// It stamps Event.BeginAt, Event.EndAt and the statistics of rows.
+	Clock func() time.Time
+
+	// SessionID, if set, will be called once for each new connection to
+	// look up its server-side id, which is set to Event.SessionID.
+	SessionID SessionIDFunc
 }
 
 func newOptions(opts []Option) *Options {
@@ -45,6 +87,20 @@ func newOptions(opts []Option) *Options {
 	return o
 }
 
+func (o *Options) classify(err error) Code {
+	if o.ErrorClassifier == nil {
+		return DefaultErrorClassifier.Classify(err)
+	}
+	return o.ErrorClassifier.Classify(err)
+}
+
+func (o *Options) now() time.Time {
+	if o.Clock == nil {
+		return time.Now()
+	}
+	return o.Clock()
+}
+
 // WithOptions sets our otsql options through a single
 // Options object.
 func WithOptions(options Options) Option {
@@ -67,6 +123,13 @@ func WithDatabaase(name string) Option {
 	}
 }
 
+// WithSystem sets database system, default inferred from driver or dsn.
+func WithSystem(system string) Option {
+	return func(o *Options) {
+		o.System = system
+	}
+}
+
 // WithPing if set to true, will enable the hook of Ping requests.
 func WithPing(b bool) Option {
 	return func(o *Options) {
@@ -103,6 +166,29 @@ func WithLastInsertID(b bool) Option {
 	}
 }
 
+// WithExecResult if set to true, will read RowsAffected and LastInsertId
+// of successful execs into Event before After hooks run.
+func WithExecResult(b bool) Option {
+	return func(o *Options) {
+		o.ExecResultB = b
+	}
+}
+
+// WithFingerprint if set to true, will enable Event.Fingerprint of calls with query.
+func WithFingerprint(b bool) Option {
+	return func(o *Options) {
+		o.FingerprintB = b
+	}
+}
+
+// WithDeferQueryEnd if set to true, will finish query events when their rows
+// are exhausted or closed.
+func WithDeferQueryEnd(b bool) Option {
+	return func(o *Options) {
+		o.DeferQueryEndB = b
+	}
+}
+
 // WithResetSession if set to true, will enable the hook of ResetSession calls.
 func WithResetSession(b bool) Option {
 	return func(o *Options) {
@@ -110,9 +196,46 @@ func WithResetSession(b bool) Option {
 	}
 }
 
+// WithStmtClose if set to true, will enable the hook of Close calls of
+// prepared statements.
+func WithStmtClose(b bool) Option {
+	return func(o *Options) {
+		o.StmtCloseB = b
+	}
+}
+
 // WithHooks set hook.
 func WithHooks(hooks ...Hook) Option {
 	return func(o *Options) {
 		o.Hooks = append(o.Hooks, hooks...)
 	}
 }
+
+// WithInterceptors appends interceptors which wrap every driver call.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(o *Options) {
+		o.Interceptors = append(o.Interceptors, interceptors...)
+	}
+}
+
+// WithErrorClassifier sets classifier used to fill Event.Code.
+func WithErrorClassifier(classifier ErrorClassifier) Option {
+	return func(o *Options) {
+		o.ErrorClassifier = classifier
+	}
+}
+
+// WithSessionID sets function to look up server-side id of each new connection,
+// such as MySQLSessionID or PostgresSessionID.
+func WithSessionID(fn SessionIDFunc) Option {
+	return func(o *Options) {
+		o.SessionID = fn
+	}
+}
+
+// WithClock sets function returns the current time, mostly to make tests deterministic.
+func WithClock(clock func() time.Time) Option {
+	return func(o *Options) {
+		o.Clock = clock
+	}
+}
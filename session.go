@@ -0,0 +1,101 @@
+package otsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// SessionIDFunc looks up the server-side id of conn.
+// conn is the connection returned by the wrapped driver, calls on it are not hooked.
+type SessionIDFunc func(ctx context.Context, conn driver.Conn) (string, error)
+
+var (
+	// MySQLSessionID looks up session id by MySQL's CONNECTION_ID().
+	MySQLSessionID = SessionIDByQuery("SELECT CONNECTION_ID()")
+
+	// PostgresSessionID looks up session id by Postgres's pg_backend_pid().
+	PostgresSessionID = SessionIDByQuery("SELECT pg_backend_pid()")
+)
+
+// SessionIDByQuery returns SessionIDFunc which runs query on connection
+// and uses the first column of the first row as session id.
+func SessionIDByQuery(query string) SessionIDFunc {
+	return func(ctx context.Context, conn driver.Conn) (string, error) {
+		rows, err := queryConn(ctx, conn, query)
+		if err != nil {
+			return "", err
+		}
+		defer rows.Close()
+
+		dest := make([]driver.Value, len(rows.Columns()))
+		if len(dest) == 0 {
+			return "", errors.New("session id query returns no column")
+		}
+		if err := rows.Next(dest); err != nil {
+			if errors.Is(err, io.EOF) {
+				return "", errors.New("session id query returns no row")
+			}
+			return "", err
+		}
+
+		switch v := dest[0].(type) {
+		case int64:
+			return strconv.FormatInt(v, 10), nil
+		case []byte:
+			return string(v), nil
+		default:
+			return fmt.Sprintf("%v", v), nil
+		}
+	}
+}
+
+func queryConn(ctx context.Context, conn driver.Conn, query string) (driver.Rows, error) {
+	if queryer, ok := conn.(driver.QueryerContext); ok {
+		rows, err := queryer.QueryContext(ctx, query, nil)
+		if !errors.Is(err, driver.ErrSkip) {
+			return rows, err
+		}
+	}
+
+	stmt, err := conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := stmt.Query(nil) // nolint
+	if err != nil {
+		stmt.Close()
+		return nil, err
+	}
+	return stmtRows{Rows: rows, stmt: stmt}, nil
+}
+
+// stmtRows closes stmt after rows.
+type stmtRows struct {
+	driver.Rows
+	stmt driver.Stmt
+}
+
+func (r stmtRows) Close() error {
+	err := r.Rows.Close()
+	if serr := r.stmt.Close(); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// sessionID looks up session id of conn, failure is ignored as session id
+// is only informational.
+func (o *Options) sessionID(ctx context.Context, conn driver.Conn) string {
+	if o.SessionID == nil || conn == nil {
+		return ""
+	}
+	id, err := o.SessionID(ctx, conn)
+	if err != nil {
+		return ""
+	}
+	return id
+}
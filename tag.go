@@ -0,0 +1,28 @@
+package otsql
+
+import "context"
+
+type tagsKey struct{}
+
+// WithTags returns a copy of ctx carrying tags, which are copied to
+// Event.Tags of every call made with the returned context.
+// keyvals are pairs of key and value, such as "tenant", "j2gg0s", a trailing
+// key without value is ignored. Tags already carried by ctx are kept unless
+// overridden by keyvals.
+func WithTags(ctx context.Context, keyvals ...string) context.Context {
+	parent := TagsFromContext(ctx)
+	tags := make(map[string]string, len(parent)+len(keyvals)/2)
+	for k, v := range parent {
+		tags[k] = v
+	}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		tags[keyvals[i]] = keyvals[i+1]
+	}
+	return context.WithValue(ctx, tagsKey{}, tags)
+}
+
+// TagsFromContext returns tags carried by ctx, which should not be modified.
+func TagsFromContext(ctx context.Context) map[string]string {
+	tags, _ := ctx.Value(tagsKey{}).(map[string]string)
+	return tags
+}
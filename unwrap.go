@@ -0,0 +1,51 @@
+package otsql
+
+import "database/sql/driver"
+
+// UnwrapConn returns the driver.Conn wrapped by otsql, such as the one
+// passed to the function of sql.Conn.Raw, or conn itself if it is not wrapped.
+func UnwrapConn(conn driver.Conn) driver.Conn {
+	for {
+		u, ok := conn.(connUnwrapper)
+		if !ok {
+			return conn
+		}
+		conn = u.Unwrap()
+	}
+}
+
+// UnwrapStmt returns the driver.Stmt wrapped by otsql,
+// or stmt itself if it is not wrapped.
+func UnwrapStmt(stmt driver.Stmt) driver.Stmt {
+	for {
+		u, ok := stmt.(stmtUnwrapper)
+		if !ok {
+			return stmt
+		}
+		stmt = u.Unwrap()
+	}
+}
+
+// UnwrapRows returns the driver.Rows wrapped by otsql,
+// or rows itself if it is not wrapped.
+func UnwrapRows(rows driver.Rows) driver.Rows {
+	for {
+		u, ok := rows.(rowsUnwrapper)
+		if !ok {
+			return rows
+		}
+		rows = u.Unwrap()
+	}
+}
+
+// UnwrapDriver returns the driver.Driver wrapped by otsql, such as the one
+// returned by sql.DB.Driver, or dri itself if it is not wrapped.
+func UnwrapDriver(dri driver.Driver) driver.Driver {
+	for {
+		u, ok := dri.(driverUnwrapper)
+		if !ok {
+			return dri
+		}
+		dri = u.Unwrap()
+	}
+}